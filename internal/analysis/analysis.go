@@ -0,0 +1,271 @@
+package analysis
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/it-ankka/battleline/internal/bot"
+	"github.com/it-ankka/battleline/internal/gamelogic"
+)
+
+type Severity string
+
+const (
+	SeverityNone     Severity = ""
+	SeverityMistake  Severity = "mistake"
+	SeverityBlunder  Severity = "blunder"
+	mistakeThreshold          = 0.2
+	blunderThreshold          = 0.35
+	// Number of moves listed in Report.Mistakes
+	maxMistakes = 5
+)
+
+// Evaluations are the mover's chance of winning from what they could see at the time
+type MoveAnalysis struct {
+	Turn   int                `json:"turn"`
+	Player int                `json:"player"`
+	Move   gamelogic.MoveData `json:"move"`
+	Before float64            `json:"before"`
+	After  float64            `json:"after"`
+	// The bot's choice is only searched when the player had more than one legal move
+	BestMove   *gamelogic.MoveData `json:"bestMove,omitempty"`
+	BestAfter  float64             `json:"bestAfter"`
+	MatchesBot bool                `json:"matchesBot"`
+	// Difference between the bot's move and the played move
+	Loss     float64  `json:"loss"`
+	Severity Severity `json:"severity,omitempty"`
+}
+
+// A lane is decided once the weakest side one player can end up with beats the best
+// side the opponent can still build from the troops that have not been played. Like
+// when proving a claim, unplayed tactics cards are left out. DecidedAfter is the index
+// of the move after which that happened for the last time, since guile tactics can
+// open a lane again, and -1 if the lane was never decided.
+type LaneAnalysis struct {
+	Lane         int `json:"lane"`
+	Player       int `json:"player"`
+	DecidedAfter int `json:"decidedAfter"`
+	ClaimedAfter int `json:"claimedAfter"`
+}
+
+type Report struct {
+	Seed   uint64         `json:"seed,string"`
+	Winner int            `json:"winner"`
+	Moves  []MoveAnalysis `json:"moves"`
+	// Indexes of the worst moves, worst first
+	Mistakes []int          `json:"mistakes"`
+	Lanes    []LaneAnalysis `json:"lanes"`
+}
+
+// Win chance of a player in one position, from that player's view
+type evaluation struct {
+	idx       int
+	playerIdx int
+	state     *gamelogic.PrivateGameState
+	value     float64
+}
+
+type analyzer struct {
+	seed        uint64
+	evaluations []*evaluation
+	// Positions are evaluated once for each player, by move index and player
+	positions map[[2]int]*evaluation
+}
+
+// Replays the game and evaluates every move by sampling the hidden cards the mover
+// could not see. The bot searches each position with the budget to find the move
+// it prefers, which is evaluated the same way.
+func Analyze(seed uint64, events []gamelogic.Event, samples int, budget bot.Budget) (*Report, error) {
+	final, err := gamelogic.Replay(seed, events)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &analyzer{seed: seed, positions: map[[2]int]*evaluation{}}
+	engine := bot.NewISMCTSBot(budget, seed)
+	report := &Report{Seed: seed, Winner: final.Winner(), Moves: []MoveAnalysis{}, Mistakes: []int{}}
+
+	lanes := make([]LaneAnalysis, len(final.Lanes))
+	for i := range lanes {
+		lanes[i] = LaneAnalysis{Lane: i, Player: gamelogic.NoWinner, DecidedAfter: -1, ClaimedAfter: -1}
+	}
+
+	// The positions are collected while replaying and evaluated together afterwards
+	var before, after, bestAfter []*evaluation
+
+	gs := gamelogic.NewGameStateWithSeed(seed)
+	for _, event := range events {
+		if event.Move == nil {
+			continue
+		}
+		idx := len(report.Moves)
+		playerIdx := event.Player
+		state := gs.GetPrivateGameState(playerIdx)
+
+		analysis := MoveAnalysis{Turn: event.Turn, Player: playerIdx, Move: *event.Move}
+		before = append(before, a.position(idx, gs, playerIdx))
+
+		var best *evaluation
+		if len(state.LegalMoves) > 1 {
+			bestMove := engine.ChooseMove(state)
+			analysis.BestMove = &bestMove
+			analysis.MatchesBot = bestMove.String() == event.Move.String()
+			if !analysis.MatchesBot {
+				bestState := gs.Clone()
+				bestState.ExecutePlayerMove(playerIdx, &bestMove)
+				// Uses the same random deals as the played move to keep the comparison fair
+				best = a.add(idx+1, bestState, playerIdx)
+			}
+		}
+
+		gs.ExecutePlayerMove(playerIdx, event.Move)
+		after = append(after, a.position(idx+1, gs, playerIdx))
+		if best == nil {
+			best = after[idx]
+		}
+		bestAfter = append(bestAfter, best)
+
+		report.Moves = append(report.Moves, analysis)
+		updateLanes(lanes, gs, idx)
+	}
+
+	a.evaluateAll(samples)
+
+	for idx := range report.Moves {
+		analysis := &report.Moves[idx]
+		analysis.Before = before[idx].value
+		analysis.After = after[idx].value
+		analysis.BestAfter = bestAfter[idx].value
+
+		analysis.Loss = max(analysis.BestAfter-analysis.After, 0)
+		switch {
+		case analysis.Loss >= blunderThreshold:
+			analysis.Severity = SeverityBlunder
+		case analysis.Loss >= mistakeThreshold:
+			analysis.Severity = SeverityMistake
+		}
+		if analysis.Severity != SeverityNone {
+			report.Mistakes = append(report.Mistakes, idx)
+		}
+	}
+	sort.SliceStable(report.Mistakes, func(i, j int) bool {
+		return report.Moves[report.Mistakes[i]].Loss > report.Moves[report.Mistakes[j]].Loss
+	})
+	report.Mistakes = report.Mistakes[:min(len(report.Mistakes), maxMistakes)]
+	report.Lanes = lanes
+
+	return report, nil
+}
+
+// Position idx is the position before move idx
+func (a *analyzer) position(idx int, gs *gamelogic.GameState, playerIdx int) *evaluation {
+	key := [2]int{idx, playerIdx}
+	if e, ok := a.positions[key]; ok {
+		return e
+	}
+	e := a.add(idx, gs, playerIdx)
+	a.positions[key] = e
+	return e
+}
+
+// The state is copied since the replay keeps changing it
+func (a *analyzer) add(idx int, gs *gamelogic.GameState, playerIdx int) *evaluation {
+	e := &evaluation{idx: idx, playerIdx: playerIdx, state: gs.Clone().GetPrivateGameState(playerIdx)}
+	a.evaluations = append(a.evaluations, e)
+	return e
+}
+
+// Every position gets its own random source, so the values do not depend on how the
+// work is split between the workers
+func (a *analyzer) evaluateAll(samples int) {
+	jobs := make(chan *evaluation)
+	var wg sync.WaitGroup
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range jobs {
+				rng := rand.New(rand.NewPCG(a.seed, uint64(e.idx)))
+				e.value = bot.Evaluate(e.state, e.playerIdx, samples, rng)
+			}
+		}()
+	}
+	for _, e := range a.evaluations {
+		jobs <- e
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+func updateLanes(lanes []LaneAnalysis, gs *gamelogic.GameState, idx int) {
+	unplayed := gamelogic.NewCardSet(gs.TroopDeck).
+		Union(gamelogic.NewCardSet(gs.PlayerHands[0])).
+		Union(gamelogic.NewCardSet(gs.PlayerHands[1]))
+
+	for laneIdx := range lanes {
+		lane := &lanes[laneIdx]
+		if lane.ClaimedAfter >= 0 {
+			continue
+		}
+
+		decided := gamelogic.NoWinner
+		if claimed := gs.Lanes[laneIdx].Claimed; claimed != gamelogic.NotClaimed {
+			lane.ClaimedAfter = idx
+			decided = claimed - 1
+		} else {
+			for _, playerIdx := range []int{0, 1} {
+				if isDecided(&gs.Lanes[laneIdx], playerIdx, unplayed) {
+					decided = playerIdx
+				}
+			}
+		}
+
+		switch {
+		case decided == gamelogic.NoWinner:
+			lane.Player, lane.DecidedAfter = gamelogic.NoWinner, -1
+		case lane.DecidedAfter < 0 || lane.Player != decided:
+			lane.Player, lane.DecidedAfter = decided, idx
+		}
+	}
+}
+
+// Whether playerIdx wins the lane however both sides are completed
+func isDecided(lane *gamelogic.Lane, playerIdx int, unplayed gamelogic.CardSet) bool {
+	opponentIdx := 1 - playerIdx
+	maxCards := lane.MaxCards()
+	if lane.Cards[playerIdx].BestPossibleValue(unplayed, maxCards, lane.Fog) == 0 {
+		return false
+	}
+
+	lowest := lowestValue(lane, playerIdx)
+	opponentBest := lane.Cards[opponentIdx].BestPossibleValue(unplayed, maxCards, lane.Fog)
+	if lowest != opponentBest {
+		return lowest > opponentBest
+	}
+	// Ties go to the side completed first
+	return lane.IsSideComplete(playerIdx) &&
+		(!lane.IsSideComplete(opponentIdx) || lane.PlayerCompletedFirst(playerIdx))
+}
+
+// Lowest value the side can have once complete. Every complete side is at least a fray
+// and every card is worth at least one.
+func lowestValue(lane *gamelogic.Lane, playerIdx int) int {
+	if lane.IsSideComplete(playerIdx) {
+		return lane.SideValue(playerIdx)
+	}
+	side := lane.Cards[playerIdx]
+	value := lane.MaxCards() - len(side)
+	for _, card := range side {
+		if card.IsWildcard() {
+			value++
+		} else {
+			value += card.Value
+		}
+	}
+	if !lane.Fog {
+		value += int(gamelogic.FormationFray) * 100
+	}
+	return value
+}
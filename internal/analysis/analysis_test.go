@@ -0,0 +1,100 @@
+package analysis
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/it-ankka/battleline/internal/bot"
+	"github.com/it-ankka/battleline/internal/gamelogic"
+	"github.com/it-ankka/battleline/internal/simulate"
+)
+
+func TestAnalyze(t *testing.T) {
+	const seed = 3
+	gs, err := simulate.PlayGame([2]bot.Strategy{bot.NewGreedyBot(), bot.NewRandomBot(seed)}, seed)
+	if err != nil {
+		t.Fatalf("PlayGame() error = %v", err)
+	}
+
+	report, err := Analyze(seed, gs.Events, 8, bot.Budget{Iterations: 50})
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+
+	moves := 0
+	for _, event := range gs.Events {
+		if event.Move != nil {
+			moves++
+		}
+	}
+	if len(report.Moves) != moves {
+		t.Errorf("Analyze() returned %d moves, want %d", len(report.Moves), moves)
+	}
+	if report.Winner != gs.Winner() {
+		t.Errorf("Analyze() winner = %d, want %d", report.Winner, gs.Winner())
+	}
+
+	for idx, analysis := range report.Moves {
+		for _, value := range []float64{analysis.Before, analysis.After, analysis.BestAfter} {
+			if value < 0 || value > 1 {
+				t.Errorf("move %d has evaluation %v, want between 0 and 1", idx, value)
+			}
+		}
+	}
+
+	if len(report.Mistakes) > maxMistakes {
+		t.Errorf("Analyze() listed %d mistakes, want at most %d", len(report.Mistakes), maxMistakes)
+	}
+	if !sort.SliceIsSorted(report.Mistakes, func(i, j int) bool {
+		return report.Moves[report.Mistakes[i]].Loss > report.Moves[report.Mistakes[j]].Loss
+	}) {
+		t.Errorf("mistakes %v are not sorted by loss", report.Mistakes)
+	}
+
+	claimed := 0
+	for _, lane := range report.Lanes {
+		if gs.Lanes[lane.Lane].Claimed == gamelogic.NotClaimed {
+			continue
+		}
+		claimed++
+		if lane.Player != gs.Lanes[lane.Lane].Claimed-1 {
+			t.Errorf("lane %d player = %d, want %d", lane.Lane, lane.Player, gs.Lanes[lane.Lane].Claimed-1)
+		}
+		if lane.DecidedAfter < 0 || lane.ClaimedAfter < lane.DecidedAfter {
+			t.Errorf("lane %d decided after move %d and claimed after move %d", lane.Lane, lane.DecidedAfter, lane.ClaimedAfter)
+		}
+	}
+	if claimed == 0 {
+		t.Errorf("no lane was claimed")
+	}
+}
+
+func TestIsDecided(t *testing.T) {
+	parse := func(notations ...string) gamelogic.Deck {
+		deck := gamelogic.Deck{}
+		for _, notation := range notations {
+			card, err := gamelogic.ParseCard(notation)
+			if err != nil {
+				t.Fatalf("ParseCard(%q) error = %v", notation, err)
+			}
+			deck = append(deck, card)
+		}
+		return deck
+	}
+
+	lane := &gamelogic.Lane{}
+	lane.Cards[0] = parse("R8", "R9")
+	lane.Cards[1] = parse("B1", "G2")
+	// Only low troops are left, so the opponent cannot build more than a fray
+	unplayed := gamelogic.NewCardSet(parse("Y1", "O5", "P7"))
+
+	if !isDecided(lane, 0, unplayed) {
+		t.Errorf("isDecided() = false for an incomplete side that beats anything the opponent can reach")
+	}
+	if isDecided(lane, 1, unplayed) {
+		t.Errorf("isDecided() = true for the losing side")
+	}
+	if isDecided(lane, 0, gamelogic.NewCardSet(parse("B3", "R10"))) {
+		t.Errorf("isDecided() = true while the opponent can still build a skirmish")
+	}
+}
@@ -0,0 +1,53 @@
+package bot
+
+import (
+	"errors"
+	"math/rand/v2"
+	"sort"
+
+	"github.com/it-ankka/battleline/internal/gamelogic"
+)
+
+// Chooses the next move for the active player from what that player can see.
+// The returned move must be one of state.LegalMoves.
+type Strategy interface {
+	ChooseMove(state *gamelogic.PrivateGameState) gamelogic.MoveData
+}
+
+var ErrUnknownStrategy = errors.New("Unknown strategy.")
+
+// Constructors for the strategies that can be picked by name
+var strategies = map[string]func(seed uint64) Strategy{
+	"random": func(seed uint64) Strategy { return NewRandomBot(seed) },
+	"greedy": func(seed uint64) Strategy { return NewGreedyBot() },
+}
+
+func New(name string, seed uint64) (Strategy, error) {
+	newStrategy, ok := strategies[name]
+	if !ok {
+		return nil, ErrUnknownStrategy
+	}
+	return newStrategy(seed), nil
+}
+
+func Names() []string {
+	names := []string{}
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Plays a uniformly random legal move
+type RandomBot struct {
+	rng *rand.Rand
+}
+
+func NewRandomBot(seed uint64) *RandomBot {
+	return &RandomBot{rng: rand.New(rand.NewPCG(seed, seed))}
+}
+
+func (b *RandomBot) ChooseMove(state *gamelogic.PrivateGameState) gamelogic.MoveData {
+	return state.LegalMoves[b.rng.IntN(len(state.LegalMoves))]
+}
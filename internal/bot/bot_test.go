@@ -0,0 +1,34 @@
+package bot_test
+
+import (
+	"testing"
+
+	"github.com/it-ankka/battleline/internal/bot"
+	"github.com/it-ankka/battleline/internal/simulate"
+)
+
+func TestGreedyBeatsRandom(t *testing.T) {
+	names := [2]string{"greedy", "random"}
+	players := [2]bot.Strategy{bot.NewGreedyBot(), bot.NewRandomBot(1)}
+
+	// The seeds are fixed so the result is the same on every run, and the threshold
+	// leaves a margin for changes in the greedy scoring
+	summary, err := simulate.Run(names, players, 50, 1)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if summary.WinRate[0] < 0.75 {
+		t.Errorf("greedy win rate against random = %.2f, want at least 0.75", summary.WinRate[0])
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range bot.Names() {
+		if _, err := bot.New(name, 1); err != nil {
+			t.Errorf("New(%q) error = %v", name, err)
+		}
+	}
+	if _, err := bot.New("unknown", 1); err != bot.ErrUnknownStrategy {
+		t.Errorf("New(unknown) error = %v, want %v", err, bot.ErrUnknownStrategy)
+	}
+}
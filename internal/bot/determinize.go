@@ -0,0 +1,143 @@
+package bot
+
+import (
+	"math/rand/v2"
+
+	"github.com/it-ankka/battleline/internal/gamelogic"
+)
+
+// Tactics cards the player has not seen
+func unseenTactics(state *gamelogic.PrivateGameState) gamelogic.Deck {
+	seen := map[gamelogic.Card]bool{}
+	decks := []gamelogic.Deck{state.PlayerHand, state.DiscardPile}
+	for _, lane := range state.Lanes {
+		decks = append(decks, lane.Cards[0], lane.Cards[1], lane.Environment[0], lane.Environment[1])
+	}
+	for _, deck := range decks {
+		for _, c := range deck {
+			seen[c] = true
+		}
+	}
+
+	unseen := gamelogic.Deck{}
+	for _, c := range gamelogic.CreateTacticsDeck() {
+		if !seen[c] {
+			unseen = append(unseen, c)
+		}
+	}
+	return unseen
+}
+
+// Builds a full game state that matches everything the active player can see by dealing
+// the unseen cards at random into the opponent's hand and the two decks
+func Determinize(state *gamelogic.PrivateGameState, rng *rand.Rand) *gamelogic.GameState {
+	return DeterminizeFor(state, state.ActivePlayer, rng)
+}
+
+// Like Determinize for a state seen by playerIdx, who does not have to be the active player
+func DeterminizeFor(state *gamelogic.PrivateGameState, playerIdx int, rng *rand.Rand) *gamelogic.GameState {
+	troops := unseenTroops(state).Deck().Shuffle(rng)
+	tactics := unseenTactics(state).Shuffle(rng)
+	opponentTroops, opponentTactics := opponentHandSizes(state, len(troops), len(tactics))
+
+	return newDeal(state, playerIdx,
+		append(troops[:opponentTroops].Copy(), tactics[:opponentTactics]...),
+		troops[opponentTroops:], tactics[opponentTactics:])
+}
+
+// Number of troop and tactics cards in the opponent's hand
+func opponentHandSizes(state *gamelogic.PrivateGameState, troops int, tactics int) (int, int) {
+	opponentTactics := min(state.OpponentTacticsHandSize, tactics)
+	opponentTroops := min(state.OpponentHandSize-opponentTactics, troops)
+	return opponentTroops, opponentTactics
+}
+
+// Every way to deal the unseen cards, or false if there are more than limit of them.
+// The opponent's hand is a set, so hands with the same cards are only dealt once.
+func allDeals(state *gamelogic.PrivateGameState, playerIdx int, limit int) ([]*gamelogic.GameState, bool) {
+	troops := unseenTroops(state).Deck()
+	tactics := unseenTactics(state)
+	opponentTroops, opponentTactics := opponentHandSizes(state, len(troops), len(tactics))
+
+	// Choosing h cards out of n for the hand and ordering the rest gives n!/h! deals
+	count := 1
+	for _, sizes := range [][2]int{{len(troops), opponentTroops}, {len(tactics), opponentTactics}} {
+		for k := sizes[1] + 1; k <= sizes[0]; k++ {
+			if count *= k; count > limit {
+				return nil, false
+			}
+		}
+	}
+
+	deals := make([]*gamelogic.GameState, 0, count)
+	for _, troopSplit := range splits(troops, opponentTroops) {
+		for _, tacticsSplit := range splits(tactics, opponentTactics) {
+			hand := append(troopSplit[0].Copy(), tacticsSplit[0]...)
+			deals = append(deals, newDeal(state, playerIdx, hand, troopSplit[1], tacticsSplit[1]))
+		}
+	}
+	return deals, true
+}
+
+// Every way to split the cards into a hand of handSize cards and an ordered deck
+func splits(cards gamelogic.Deck, handSize int) [][2]gamelogic.Deck {
+	result := [][2]gamelogic.Deck{}
+	var pick func(i int, hand gamelogic.Deck, rest gamelogic.Deck)
+	pick = func(i int, hand gamelogic.Deck, rest gamelogic.Deck) {
+		if len(hand) == handSize {
+			for _, deck := range permutations(append(rest.Copy(), cards[i:]...)) {
+				result = append(result, [2]gamelogic.Deck{hand.Copy(), deck})
+			}
+			return
+		}
+		if i == len(cards) {
+			return
+		}
+		pick(i+1, append(hand.Copy(), cards[i]), rest)
+		pick(i+1, hand, append(rest.Copy(), cards[i]))
+	}
+	pick(0, gamelogic.Deck{}, gamelogic.Deck{})
+	return result
+}
+
+func permutations(cards gamelogic.Deck) []gamelogic.Deck {
+	if len(cards) <= 1 {
+		return []gamelogic.Deck{cards.Copy()}
+	}
+	result := []gamelogic.Deck{}
+	for i, card := range cards {
+		rest := append(cards[:i].Copy(), cards[i+1:]...)
+		for _, permutation := range permutations(rest) {
+			result = append(result, append(gamelogic.Deck{card}, permutation...))
+		}
+	}
+	return result
+}
+
+// Builds the full game state with the given cards in the opponent's hand and the decks
+func newDeal(state *gamelogic.PrivateGameState, playerIdx int, opponentHand gamelogic.Deck, troopDeck gamelogic.Deck, tacticsDeck gamelogic.Deck) *gamelogic.GameState {
+	gs := &gamelogic.GameState{
+		ActivePlayer:     state.ActivePlayer,
+		Lanes:            state.Lanes,
+		TacticsPlayed:    state.TacticsPlayed,
+		DiscardPile:      state.DiscardPile.Copy(),
+		ScoutDrawsLeft:   state.ScoutDrawsLeft,
+		ScoutReturnsLeft: state.ScoutReturnsLeft,
+
+		ConsecutivePasses: state.ConsecutivePasses,
+	}
+	gs.TurnPhase, _ = gamelogic.ParseTurnPhase(state.TurnPhase)
+	for i := range gs.Lanes {
+		for j := range gs.Lanes[i].Cards {
+			gs.Lanes[i].Cards[j] = state.Lanes[i].Cards[j].Copy()
+			gs.Lanes[i].Environment[j] = state.Lanes[i].Environment[j].Copy()
+		}
+	}
+
+	gs.PlayerHands[playerIdx] = state.PlayerHand.Copy()
+	gs.PlayerHands[1-playerIdx] = opponentHand
+	gs.TroopDeck = troopDeck.Copy()
+	gs.TacticsDeck = tacticsDeck.Copy()
+
+	return gs
+}
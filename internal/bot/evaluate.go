@@ -0,0 +1,102 @@
+package bot
+
+import (
+	"math/rand/v2"
+
+	"github.com/it-ankka/battleline/internal/gamelogic"
+)
+
+// Estimates the chance that playerIdx wins from what they can see in the state by
+// dealing the hidden cards at random and playing each deal out with the rollout
+// policy. Draws count as half a win.
+func Evaluate(state *gamelogic.PrivateGameState, playerIdx int, samples int, rng *rand.Rand) float64 {
+	if state.GameOver {
+		return outcome(state.Winner, playerIdx)
+	}
+
+	total := 0.0
+	for range samples {
+		gs := DeterminizeFor(state, playerIdx, rng)
+		rollout(gs, rng)
+		total += outcome(gs.Winner(), playerIdx)
+	}
+	return total / float64(max(samples, 1))
+}
+
+func outcome(winner int, playerIdx int) float64 {
+	switch winner {
+	case playerIdx:
+		return 1
+	case gamelogic.NoWinner:
+		return 0.5
+	}
+	return 0
+}
+
+// Estimates the chance that playerIdx wins each lane from what they can see in the
+// state. Every sample completes both sides of the lane at random, the opponent's from
+// the troops the player has not seen and the player's from their own hand and the
+// unseen troops left. The completed sides are compared by their lane value, with ties
+// going to the side completed first. Tactics cards left to play are not considered.
+func LaneWinProbabilities(state *gamelogic.PrivateGameState, playerIdx int, samples int, rng *rand.Rand) []float64 {
+	hand := gamelogic.NewCardSet(state.PlayerHand).Deck()
+	unseen := unseenTroops(state).Deck()
+
+	probabilities := make([]float64, len(state.Lanes))
+	for laneIdx := range state.Lanes {
+		lane := &state.Lanes[laneIdx]
+		if lane.Claimed != gamelogic.NotClaimed {
+			probabilities[laneIdx] = outcome(lane.Claimed-1, playerIdx)
+			continue
+		}
+		for range samples {
+			probabilities[laneIdx] += laneOutcome(lane, playerIdx, hand, unseen, rng)
+		}
+		probabilities[laneIdx] /= float64(max(samples, 1))
+	}
+	return probabilities
+}
+
+// Completes both sides of the lane at random and scores the result. A side left
+// without enough cards loses to a complete one.
+func laneOutcome(lane *gamelogic.Lane, playerIdx int, hand gamelogic.Deck, unseen gamelogic.Deck, rng *rand.Rand) float64 {
+	var sides [2]gamelogic.Deck
+	complete := func(idx int, cards gamelogic.Deck) gamelogic.Deck {
+		missing := max(lane.MaxCards()-len(lane.Cards[idx]), 0)
+		if missing <= len(cards) {
+			sides[idx] = append(lane.Cards[idx].Copy(), cards[:missing]...)
+			return cards[missing:]
+		}
+		return cards
+	}
+	left := complete(1-playerIdx, unseen.Shuffle(rng))
+	complete(playerIdx, append(hand.Copy(), left...).Shuffle(rng))
+
+	switch {
+	case sides[0] == nil && sides[1] == nil:
+		return 0.5
+	case sides[1-playerIdx] == nil:
+		return 1
+	case sides[playerIdx] == nil:
+		return 0
+	}
+
+	playerValue := sides[playerIdx].GetLaneValue(lane.Fog)
+	opponentValue := sides[1-playerIdx].GetLaneValue(lane.Fog)
+	playerWins := playerValue > opponentValue
+	if playerValue == opponentValue {
+		switch {
+		case lane.IsSideComplete(playerIdx) && lane.IsSideComplete(1-playerIdx):
+			playerWins = lane.PlayerCompletedFirst(playerIdx)
+		case lane.IsSideComplete(playerIdx) || lane.IsSideComplete(1-playerIdx):
+			playerWins = lane.IsSideComplete(playerIdx)
+		default:
+			// Neither side is complete yet, so either could be completed first
+			return 0.5
+		}
+	}
+	if playerWins {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/it-ankka/battleline/internal/gamelogic"
+)
+
+func TestLaneWinProbabilities(t *testing.T) {
+	gs := gamelogic.NewGameStateWithSeed(9)
+	greedy := NewGreedyBot()
+	for range 60 {
+		move := greedy.ChooseMove(gs.GetPrivateGameState(gs.ActivePlayer))
+		gs.ExecutePlayerMove(gs.ActivePlayer, &move)
+	}
+
+	for playerIdx := range 2 {
+		state := gs.GetPrivateGameState(playerIdx)
+		probabilities := LaneWinProbabilities(state, playerIdx, 20, rand.New(rand.NewPCG(1, 1)))
+
+		for laneIdx, p := range probabilities {
+			lane := gs.Lanes[laneIdx]
+			switch {
+			case p < 0 || p > 1:
+				t.Errorf("lane %d probability = %v, want between 0 and 1", laneIdx, p)
+			case lane.Claimed != gamelogic.NotClaimed && p != outcome(lane.Claimed-1, playerIdx):
+				t.Errorf("claimed lane %d probability = %v", laneIdx, p)
+			}
+		}
+
+		// Another game that looks the same to the player must get the same estimates
+		other := DeterminizeFor(state, playerIdx, rand.New(rand.NewPCG(2, 2)))
+		otherState := other.GetPrivateGameState(playerIdx)
+		if got := LaneWinProbabilities(otherState, playerIdx, 20, rand.New(rand.NewPCG(1, 1))); !slices.Equal(got, probabilities) {
+			t.Errorf("estimates depend on hidden cards: %v and %v", probabilities, got)
+		}
+	}
+}
+
+func TestEvaluateFinishedGame(t *testing.T) {
+	for seed := range uint64(5) {
+		gs := gamelogic.NewGameStateWithSeed(seed)
+		random := NewRandomBot(seed)
+		for !gs.IsOver() {
+			move := random.ChooseMove(gs.GetPrivateGameState(gs.ActivePlayer))
+			gs.ExecutePlayerMove(gs.ActivePlayer, &move)
+		}
+
+		for playerIdx := range 2 {
+			want := map[int]float64{playerIdx: 1, 1 - playerIdx: 0, gamelogic.NoWinner: 0.5}[gs.Winner()]
+			got := Evaluate(gs.GetPrivateGameState(playerIdx), playerIdx, 10, rand.New(rand.NewPCG(seed, seed)))
+			if got != want {
+				t.Errorf("seed %d: Evaluate() for player %d = %v, want %v with winner %d", seed, playerIdx, got, want, gs.Winner())
+			}
+		}
+	}
+}
+
+func TestLaneOutcome(t *testing.T) {
+	parse := func(notations ...string) gamelogic.Deck {
+		deck := gamelogic.Deck{}
+		for _, notation := range notations {
+			card, err := gamelogic.ParseCard(notation)
+			if err != nil {
+				t.Fatalf("ParseCard(%q) error = %v", notation, err)
+			}
+			deck = append(deck, card)
+		}
+		return deck
+	}
+
+	tests := []struct {
+		name   string
+		sides  [2]gamelogic.Deck
+		hand   gamelogic.Deck
+		unseen gamelogic.Deck
+		want   float64
+	}{
+		{"stronger complete side", [2]gamelogic.Deck{parse("R8", "R9", "R10"), parse("B1", "B2")}, nil, parse("B3"), 1},
+		{"weaker completion", [2]gamelogic.Deck{parse("R1", "G5"), parse("B8", "B9")}, parse("Y7"), parse("B10"), 0},
+		{"no cards left for the opponent", [2]gamelogic.Deck{parse("R1", "G5"), parse("B8", "B9")}, parse("Y7"), nil, 1},
+		// Only the player's own B10 would give the opponent a wedge
+		{"opponent cannot use the player's hand", [2]gamelogic.Deck{parse("R6", "G7"), parse("B8", "B9")}, parse("B10"), parse("Y2"), 1},
+		{"tie between incomplete sides", [2]gamelogic.Deck{parse("R1", "G5"), parse("B1", "Y5")}, parse("O3"), parse("P3"), 0.5},
+	}
+	for _, tt := range tests {
+		lane := &gamelogic.Lane{}
+		for playerIdx, side := range tt.sides {
+			for _, card := range side {
+				lane.Cards[playerIdx] = append(lane.Cards[playerIdx], card)
+				lane.UpdateCompletion(playerIdx)
+			}
+		}
+		for seed := range uint64(10) {
+			if got := laneOutcome(lane, 0, tt.hand, tt.unseen, rand.New(rand.NewPCG(seed, seed))); got != tt.want {
+				t.Errorf("%s: laneOutcome() = %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
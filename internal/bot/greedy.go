@@ -0,0 +1,130 @@
+package bot
+
+import (
+	"github.com/it-ankka/battleline/internal/gamelogic"
+)
+
+const (
+	claimScore       = 1000
+	winnableBonus    = 100
+	completedBonus   = 200
+	moraleCost       = 30
+	guileCost        = 150
+	scoutCost        = 200
+	unscoredMoveCost = 500
+)
+
+// Scores every legal move on its own and plays the best one. Lanes are scored by
+// the best formation each side can still complete from the cards that have not
+// been seen on the board, in the discard pile or in the bot's own hand.
+type GreedyBot struct{}
+
+func NewGreedyBot() *GreedyBot {
+	return &GreedyBot{}
+}
+
+func (b *GreedyBot) ChooseMove(state *gamelogic.PrivateGameState) gamelogic.MoveData {
+	best, bestScore := state.LegalMoves[0], 0
+	for i, move := range state.LegalMoves {
+		if score := scoreMove(state, move); i == 0 || score > bestScore {
+			best, bestScore = move, score
+		}
+	}
+	return best
+}
+
+// Troop cards the player has not seen. The opponent's hand is among them.
+func unseenTroops(state *gamelogic.PrivateGameState) gamelogic.CardSet {
+	seen := gamelogic.NewCardSet(state.PlayerHand).Union(gamelogic.NewCardSet(state.DiscardPile))
+	for _, lane := range state.Lanes {
+		seen = seen.Union(gamelogic.NewCardSet(lane.Cards[0])).Union(gamelogic.NewCardSet(lane.Cards[1]))
+	}
+	return gamelogic.FullCardSet.Difference(seen)
+}
+
+func scoreMove(state *gamelogic.PrivateGameState, move gamelogic.MoveData) int {
+	playerIdx := state.ActivePlayer
+
+	switch move.Action {
+	case gamelogic.ClaimAction:
+		return claimScore
+	case gamelogic.DrawAction:
+		if *move.TacticsDeck {
+			canPlayTactic := state.TacticsPlayed[playerIdx] <= state.TacticsPlayed[1-playerIdx]
+			if state.PlayerHand.TacticsCount() == 0 && canPlayTactic {
+				return 2
+			}
+			return 0
+		}
+		return 1
+	case gamelogic.ScoutReturnAction:
+		// Low troops are the least useful cards to keep
+		if move.Card.IsTactic() {
+			return 0
+		}
+		return 11 - move.Card.Value
+	case gamelogic.PlacementAction:
+		return scorePlacement(state, move)
+	default:
+		return 0
+	}
+}
+
+func scorePlacement(state *gamelogic.PrivateGameState, move gamelogic.MoveData) int {
+	playerIdx := state.ActivePlayer
+	opponentIdx := 1 - playerIdx
+	card := *move.Card
+	unseen := unseenTroops(state)
+	available := unseen.Union(gamelogic.NewCardSet(state.PlayerHand)).Remove(card)
+
+	switch {
+	case card.IsEnvironment() || card.Tactic == gamelogic.TacticRedeploy || (move.TargetCard != nil && move.TargetCard.IsEnvironment()):
+		return -unscoredMoveCost
+	case card.Tactic == gamelogic.TacticScout:
+		return -scoutCost
+	case card.Tactic == gamelogic.TacticDeserter || card.Tactic == gamelogic.TacticTraitor:
+		lane := state.Lanes[*move.TargetLane]
+		side := lane.Cards[opponentIdx]
+		after := side.RemoveAt(side.FindCardIdx(*move.TargetCard))
+		damage := side.BestPossibleValue(unseen, lane.MaxCards(), lane.Fog) -
+			after.BestPossibleValue(unseen, lane.MaxCards(), lane.Fog)
+		if card.Tactic == gamelogic.TacticTraitor {
+			damage += scoreLane(&state.Lanes[*move.Lane], playerIdx, *move.TargetCard, available, unseen)
+		}
+		return damage - guileCost
+	}
+
+	score := scoreLane(&state.Lanes[*move.Lane], playerIdx, card, available, unseen)
+	if card.IsTactic() {
+		score -= moraleCost
+	}
+	return score
+}
+
+// How much placing the card changes the side's potential, with bonuses when the side
+// can still beat the opponent's best possible formation
+func scoreLane(lane *gamelogic.Lane, playerIdx int, card gamelogic.Card, available gamelogic.CardSet, unseen gamelogic.CardSet) int {
+	opponentIdx := 1 - playerIdx
+	maxCards := lane.MaxCards()
+	placed := *lane
+	placed.Cards[playerIdx] = append(lane.Cards[playerIdx].Copy(), card)
+
+	before := lane.Cards[playerIdx].BestPossibleValue(available.Add(card), maxCards, lane.Fog)
+	potential := placed.Cards[playerIdx].BestPossibleValue(available, maxCards, lane.Fog)
+	if placed.IsSideComplete(playerIdx) {
+		potential = placed.SideValue(playerIdx)
+	}
+	opponentBest := lane.Cards[opponentIdx].BestPossibleValue(unseen, maxCards, lane.Fog)
+	if lane.IsSideComplete(opponentIdx) {
+		opponentBest = lane.SideValue(opponentIdx)
+	}
+
+	score := potential - before
+	if potential > opponentBest {
+		score += winnableBonus
+		if placed.IsSideComplete(playerIdx) {
+			score += completedBonus
+		}
+	}
+	return score
+}
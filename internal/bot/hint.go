@@ -0,0 +1,169 @@
+package bot
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+
+	"github.com/it-ankka/battleline/internal/gamelogic"
+)
+
+type Hint struct {
+	Move   gamelogic.MoveData `json:"move"`
+	Score  int                `json:"score"`
+	Reason string             `json:"reason"`
+}
+
+// Ranks the legal moves with the greedy scores and explains the best ones. Only the
+// player's own view of the game is used.
+func Hints(state *gamelogic.PrivateGameState, count int) []Hint {
+	hints := []Hint{}
+	for _, move := range state.LegalMoves {
+		hints = append(hints, Hint{Move: move, Score: scoreMove(state, move)})
+	}
+	sort.SliceStable(hints, func(i, j int) bool {
+		return hints[i].Score > hints[j].Score
+	})
+
+	hints = hints[:min(len(hints), count)]
+	for i := range hints {
+		hints[i].Reason = hintReason(state, hints[i].Move)
+	}
+	return hints
+}
+
+const (
+	// Moves scored this far below the best legal move are checked for blunders
+	blunderMargin = winnableBonus
+	// A blunder has to lower the player's chance of winning this much, the same as a
+	// blunder in the post-game analysis
+	blunderLoss    = 0.35
+	blunderSamples = 100
+)
+
+// Checks the move against the best legal move in the state it was played from and
+// returns the better move if the difference is large enough to be a blunder. Moves
+// the greedy scores find suspicious are confirmed by playing out random deals after
+// both moves.
+func Blunder(state *gamelogic.PrivateGameState, move gamelogic.MoveData, rng *rand.Rand) (Hint, bool) {
+	hints := Hints(state, 1)
+	if len(hints) == 0 || hints[0].Move.String() == move.String() {
+		return Hint{}, false
+	}
+	if scoreMove(state, move)+blunderMargin > hints[0].Score {
+		return Hint{}, false
+	}
+
+	// Both moves are played out in the same deals to keep the comparison fair
+	seed := rng.Uint64()
+	loss := evaluateMove(state, hints[0].Move, seed) - evaluateMove(state, move, seed)
+	if loss < blunderLoss {
+		return Hint{}, false
+	}
+	return hints[0], true
+}
+
+// Chance that the active player wins after the move, from what they can see
+func evaluateMove(state *gamelogic.PrivateGameState, move gamelogic.MoveData, seed uint64) float64 {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	total := 0.0
+	for range blunderSamples {
+		gs := Determinize(state, rng)
+		gs.ExecutePlayerMove(state.ActivePlayer, &move)
+		rollout(gs, rng)
+		total += outcome(gs.Winner(), state.ActivePlayer)
+	}
+	return total / blunderSamples
+}
+
+func formationName(value int) string {
+	return strings.ToLower(gamelogic.Formation(value / 100).String())
+}
+
+func hintReason(state *gamelogic.PrivateGameState, move gamelogic.MoveData) string {
+	switch move.Action {
+	case gamelogic.ClaimAction:
+		return fmt.Sprintf("claims lane %d", *move.Lane+1)
+	case gamelogic.EndClaimAction:
+		return "nothing else can be claimed"
+	case gamelogic.PassAction:
+		return "no card can be played"
+	case gamelogic.DrawAction:
+		if *move.TacticsDeck {
+			return "draws a tactics card"
+		}
+		return "draws a troop card"
+	case gamelogic.ScoutReturnAction:
+		return fmt.Sprintf("%s is the least useful card to keep", move.Card.Notation())
+	case gamelogic.PlacementAction:
+		return placementReason(state, move)
+	}
+	return ""
+}
+
+func placementReason(state *gamelogic.PrivateGameState, move gamelogic.MoveData) string {
+	playerIdx := state.ActivePlayer
+	card := *move.Card
+
+	switch card.Tactic {
+	case gamelogic.TacticFog:
+		return fmt.Sprintf("lane %d is decided by sum only", *move.Lane+1)
+	case gamelogic.TacticMud:
+		return fmt.Sprintf("lane %d needs four cards per side", *move.Lane+1)
+	case gamelogic.TacticScout:
+		return "draws three cards and returns two"
+	case gamelogic.TacticRedeploy:
+		if move.Lane == nil {
+			return fmt.Sprintf("discards %s from lane %d", move.TargetCard.Notation(), *move.TargetLane+1)
+		}
+		return fmt.Sprintf("moves %s from lane %d to lane %d", move.TargetCard.Notation(), *move.TargetLane+1, *move.Lane+1)
+	case gamelogic.TacticDeserter, gamelogic.TacticTraitor:
+		lane := state.Lanes[*move.TargetLane]
+		unseen := unseenTroops(state)
+		side := lane.Cards[1-playerIdx]
+		before := side.BestPossibleValue(unseen, lane.MaxCards(), lane.Fog)
+		verb := "removes"
+		if card.Tactic == gamelogic.TacticTraitor {
+			verb = "takes"
+		}
+		reason := fmt.Sprintf("%s opponent's %s from lane %d", verb, move.TargetCard.Notation(), *move.TargetLane+1)
+		if !lane.Fog && !move.TargetCard.IsEnvironment() && before >= 100 {
+			reason += ", breaking their " + formationName(before)
+		}
+		return reason
+	}
+
+	lane := state.Lanes[*move.Lane]
+	maxCards := lane.MaxCards()
+	unseen := unseenTroops(state)
+	available := unseen.Union(gamelogic.NewCardSet(state.PlayerHand)).Remove(card)
+	after := append(lane.Cards[playerIdx].Copy(), card)
+	potential := after.BestPossibleValue(available, maxCards, lane.Fog)
+	opponentSide := lane.Cards[1-playerIdx]
+	opponentBest := opponentSide.BestPossibleValue(unseen, maxCards, lane.Fog)
+	laneNumber := *move.Lane + 1
+
+	reason := ""
+	switch {
+	case potential == 0:
+		reason = fmt.Sprintf("lane %d cannot be completed, but every other move is worse", laneNumber)
+	case lane.Fog && len(after) >= maxCards:
+		reason = fmt.Sprintf("completes a sum of %d in lane %d", potential, laneNumber)
+	case lane.Fog:
+		reason = fmt.Sprintf("builds toward a sum of up to %d in lane %d", potential, laneNumber)
+	case len(after) >= maxCards:
+		reason = fmt.Sprintf("completes %s in lane %d", formationName(potential), laneNumber)
+	default:
+		reason = fmt.Sprintf("builds toward %s in lane %d", formationName(potential), laneNumber)
+	}
+
+	if len(opponentSide) > 0 && potential > opponentBest {
+		if lane.Fog || opponentBest == 0 {
+			reason += ", beating anything the opponent can reach"
+		} else {
+			reason += ", blocks opponent " + formationName(opponentBest)
+		}
+	}
+	return reason
+}
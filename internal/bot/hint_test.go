@@ -0,0 +1,64 @@
+package bot
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/it-ankka/battleline/internal/gamelogic"
+)
+
+func TestHints(t *testing.T) {
+	gs := gamelogic.NewGameStateWithSeed(7)
+	random := NewRandomBot(7)
+
+	for !gs.IsOver() {
+		state := gs.GetPrivateGameState(gs.ActivePlayer)
+		hints := Hints(state, 3)
+		if len(hints) != min(len(state.LegalMoves), 3) {
+			t.Fatalf("Hints() returned %d hints for %d legal moves", len(hints), len(state.LegalMoves))
+		}
+		for i, hint := range hints {
+			if err := gs.ValidatePlayerMove(gs.ActivePlayer, &hint.Move); err != nil {
+				t.Fatalf("Hints() suggested %s: %v", hint.Move, err)
+			}
+			if hint.Reason == "" {
+				t.Errorf("Hints() gave no reason for %s", hint.Move)
+			}
+			if i > 0 && hint.Score > hints[i-1].Score {
+				t.Errorf("Hints() is not sorted by score in %s", gs.Position())
+			}
+		}
+
+		// The best move is never a blunder
+		if len(hints) > 0 {
+			if _, ok := Blunder(state, hints[0].Move, rand.New(rand.NewPCG(7, 7))); ok {
+				t.Errorf("Blunder(%s) = true for the best move", hints[0].Move)
+			}
+		}
+
+		move := random.ChooseMove(state)
+		gs.ExecutePlayerMove(gs.ActivePlayer, &move)
+	}
+}
+
+func TestBlunder(t *testing.T) {
+	blunders := 0
+	for seed := uint64(0); seed < 5 && blunders == 0; seed++ {
+		gs := gamelogic.NewGameStateWithSeed(seed)
+		random := NewRandomBot(seed)
+		for !gs.IsOver() {
+			state := gs.GetPrivateGameState(gs.ActivePlayer)
+			move := random.ChooseMove(state)
+			if better, ok := Blunder(state, move, rand.New(rand.NewPCG(seed, seed))); ok {
+				blunders++
+				if err := gs.ValidatePlayerMove(gs.ActivePlayer, &better.Move); err != nil || better.Move.String() == move.String() {
+					t.Errorf("Blunder(%s) suggested %s instead", move, better.Move)
+				}
+			}
+			gs.ExecutePlayerMove(gs.ActivePlayer, &move)
+		}
+	}
+	if blunders == 0 {
+		t.Errorf("Blunder() found nothing wrong with random moves")
+	}
+}
@@ -0,0 +1,374 @@
+package bot
+
+import (
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/it-ankka/battleline/internal/gamelogic"
+)
+
+// Search limits. The search stops at whichever limit is reached first and a zero
+// value means no limit. A budget without either limit falls back to the easy one.
+type Budget struct {
+	Iterations int
+	Duration   time.Duration
+	// Positions the endgame solver may visit, or zero to leave the endgame to sampling
+	SolverNodes int
+}
+
+func (budget Budget) orDefault() Budget {
+	if budget.Iterations <= 0 && budget.Duration <= 0 {
+		return DifficultyEasy.Budget()
+	}
+	return budget
+}
+
+type Difficulty int
+
+const (
+	DifficultyEasy Difficulty = iota
+	DifficultyMedium
+	DifficultyHard
+)
+
+var difficultyNames = map[Difficulty]string{
+	DifficultyEasy:   "easy",
+	DifficultyMedium: "medium",
+	DifficultyHard:   "hard",
+}
+
+var difficultyBudgets = map[Difficulty]Budget{
+	DifficultyEasy:   {Iterations: 200},
+	DifficultyMedium: {Iterations: 2000, Duration: time.Second, SolverNodes: 20000},
+	DifficultyHard:   {Iterations: 20000, Duration: 3 * time.Second, SolverNodes: 100000},
+}
+
+func (d Difficulty) String() string {
+	return difficultyNames[d]
+}
+
+func (d Difficulty) Budget() Budget {
+	return difficultyBudgets[d]
+}
+
+func ParseDifficulty(name string) (Difficulty, bool) {
+	for difficulty, difficultyName := range difficultyNames {
+		if difficultyName == name {
+			return difficulty, true
+		}
+	}
+	return DifficultyMedium, false
+}
+
+const (
+	// Balances exploring rarely visited moves against playing the best ones
+	explorationConstant = 0.7
+	// Only the moves the greedy scores rank highest are searched at the root
+	rootCandidates = 8
+	// Deals of the hidden cards the endgame solver may try
+	solverDealLimit = 24
+)
+
+// Information set Monte Carlo tree search. Every iteration deals the hidden cards
+// at random and walks a tree shared by all deals, so moves are only compared by
+// how well they do across the hands the opponent could be holding. Each worker
+// grows its own tree and the root visits are added up at the end. A bot can be
+// shared between goroutines, since every call draws its own random sources.
+type ISMCTSBot struct {
+	Budget  Budget
+	Workers int
+	seed    uint64
+	calls   atomic.Uint64
+}
+
+func NewISMCTSBot(budget Budget, seed uint64) *ISMCTSBot {
+	return &ISMCTSBot{Budget: budget.orDefault(), Workers: runtime.NumCPU(), seed: seed}
+}
+
+func NewDifficultyBot(difficulty Difficulty, seed uint64) *ISMCTSBot {
+	return NewISMCTSBot(difficulty.Budget(), seed)
+}
+
+type searchNode struct {
+	move     gamelogic.MoveData
+	player   int
+	parent   *searchNode
+	children map[string]*searchNode
+	visits   int
+	// Number of iterations in which the move was legal
+	available int
+	reward    float64
+}
+
+func newSearchNode(parent *searchNode, move gamelogic.MoveData, player int) *searchNode {
+	return &searchNode{move: move, player: player, parent: parent, children: map[string]*searchNode{}}
+}
+
+func (n *searchNode) ucb() float64 {
+	return n.reward/float64(n.visits) + explorationConstant*math.Sqrt(math.Log(float64(n.available))/float64(n.visits))
+}
+
+func (b *ISMCTSBot) ChooseMove(state *gamelogic.PrivateGameState) gamelogic.MoveData {
+	if len(state.LegalMoves) == 1 {
+		return state.LegalMoves[0]
+	}
+
+	call := b.calls.Add(1)
+	if move, ok := b.solveEndgame(state, b.Budget.SolverNodes); ok {
+		return move
+	}
+
+	candidates := rankCandidates(state)
+	workers := max(b.Workers, 1)
+	visits := make([]map[string]int, workers)
+
+	budget := b.Budget.orDefault()
+	var deadline time.Time
+	if budget.Duration > 0 {
+		deadline = time.Now().Add(budget.Duration)
+	}
+
+	var wg sync.WaitGroup
+	for w := range workers {
+		iterations := 0
+		if budget.Iterations > 0 {
+			// Spread the remainder so the workers run exactly Iterations in total
+			iterations = budget.Iterations / workers
+			if w < budget.Iterations%workers {
+				iterations++
+			}
+			if iterations == 0 {
+				visits[w] = map[string]int{}
+				continue
+			}
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rng := rand.New(rand.NewPCG(b.seed+call, uint64(w)))
+			visits[w] = search(state, candidates, rng, iterations, deadline)
+		}()
+	}
+	wg.Wait()
+
+	total := map[string]int{}
+	for _, workerVisits := range visits {
+		for key, count := range workerVisits {
+			total[key] += count
+		}
+	}
+
+	best, bestVisits := state.LegalMoves[0], -1
+	for _, move := range state.LegalMoves {
+		if !candidates[move.String()] {
+			continue
+		}
+		if count := total[move.String()]; count > bestVisits {
+			best, bestVisits = move, count
+		}
+	}
+	return best
+}
+
+// Once only a few cards are hidden every way they can be dealt is solved, which is
+// exact when both decks are empty and the unseen cards are the opponent's hand
+func (b *ISMCTSBot) solveEndgame(state *gamelogic.PrivateGameState, maxNodes int) (gamelogic.MoveData, bool) {
+	if maxNodes <= 0 {
+		return gamelogic.MoveData{}, false
+	}
+	solution, err := SolveDeals(state, solverDealLimit, maxNodes)
+	if err != nil {
+		return gamelogic.MoveData{}, false
+	}
+	return solution.Move, true
+}
+
+// Notation of the legal moves with the best greedy scores
+func rankCandidates(state *gamelogic.PrivateGameState) map[string]bool {
+	moves := state.LegalMoves
+	scores := map[string]int{}
+	for _, move := range moves {
+		scores[move.String()] = scoreMove(state, move)
+	}
+	sorted := append([]gamelogic.MoveData{}, moves...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return scores[sorted[i].String()] > scores[sorted[j].String()]
+	})
+
+	candidates := map[string]bool{}
+	for _, move := range sorted[:min(len(sorted), rootCandidates)] {
+		candidates[move.String()] = true
+	}
+	return candidates
+}
+
+// Runs iterations until the iteration count or the deadline is reached and returns
+// the visits of each root move
+func search(state *gamelogic.PrivateGameState, candidates map[string]bool, rng *rand.Rand, iterations int, deadline time.Time) map[string]int {
+	root := newSearchNode(nil, gamelogic.MoveData{}, 1-state.ActivePlayer)
+
+	for i := 0; iterations == 0 || i < iterations; i++ {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			break
+		}
+
+		gs := Determinize(state, rng)
+		node := selectAndExpand(root, gs, candidates, rng)
+		rollout(gs, rng)
+
+		winner := gs.Winner()
+		for ; node != nil; node = node.parent {
+			node.visits++
+			switch winner {
+			case node.player:
+				node.reward += 1
+			case gamelogic.NoWinner:
+				node.reward += 0.5
+			}
+		}
+	}
+
+	visits := map[string]int{}
+	for key, child := range root.children {
+		visits[key] = child.visits
+	}
+	return visits
+}
+
+// Follows the tree with UCB among the moves legal in this deal until a move that
+// has not been tried is found, which is added to the tree and played
+func selectAndExpand(node *searchNode, gs *gamelogic.GameState, candidates map[string]bool, rng *rand.Rand) *searchNode {
+	for !gs.IsOver() {
+		playerIdx := gs.ActivePlayer
+		moves := gs.LegalMoves(playerIdx)
+
+		untried := []gamelogic.MoveData{}
+		var best *searchNode
+		for _, move := range moves {
+			if node.parent == nil && !candidates[move.String()] {
+				continue
+			}
+			child, ok := node.children[move.String()]
+			if !ok {
+				untried = append(untried, move)
+				continue
+			}
+			child.available++
+			if best == nil || child.ucb() > best.ucb() {
+				best = child
+			}
+		}
+
+		if len(untried) > 0 {
+			move := untried[rng.IntN(len(untried))]
+			child := newSearchNode(node, move, playerIdx)
+			child.available++
+			node.children[move.String()] = child
+			gs.ExecutePlayerMove(playerIdx, &move)
+			return child
+		}
+
+		gs.ExecutePlayerMove(playerIdx, &best.move)
+		node = best
+	}
+	return node
+}
+
+// Plays the game to the end with a cheap policy. Lanes are claimed as soon as
+// possible and troops go where they fit the cards already on the side. Tactics are
+// only played when no troop can be placed, as a random legal move.
+func rollout(gs *gamelogic.GameState, rng *rand.Rand) {
+	tacticsDeck := false
+	for !gs.IsOver() {
+		playerIdx := gs.ActivePlayer
+		move := gamelogic.MoveData{}
+
+		switch gs.TurnPhase {
+		case gamelogic.PlacementPhase:
+			move = rolloutPlacement(gs, rng)
+		case gamelogic.ClaimPhase:
+			move.Action = gamelogic.EndClaimAction
+			for laneIdx := range gs.Lanes {
+				if gs.PlayerCanClaimLane(playerIdx, laneIdx) {
+					move = gamelogic.MoveData{Action: gamelogic.ClaimAction, Lane: &laneIdx}
+					break
+				}
+			}
+		case gamelogic.DrawPhase:
+			tacticsDeck = len(gs.TroopDeck) == 0
+			move = gamelogic.MoveData{Action: gamelogic.DrawAction, TacticsDeck: &tacticsDeck}
+		}
+
+		if move.Action == "" {
+			moves := gs.LegalMoves(playerIdx)
+			move = moves[rng.IntN(len(moves))]
+		}
+		gs.ExecutePlayerMove(playerIdx, &move)
+	}
+}
+
+// Picks the troop and lane where the troop fits the side best, breaking ties at random
+func rolloutPlacement(gs *gamelogic.GameState, rng *rand.Rand) gamelogic.MoveData {
+	playerIdx := gs.ActivePlayer
+	best := gamelogic.MoveData{}
+	bestScore, ties := -1, 0
+
+	for cardIdx, card := range gs.PlayerHands[playerIdx] {
+		if card.IsTactic() {
+			continue
+		}
+		for laneIdx := range gs.Lanes {
+			if !gs.Lanes.PlayerCanPlaceInLane(playerIdx, laneIdx) {
+				continue
+			}
+			score := placementFit(gs.Lanes[laneIdx].Cards[playerIdx], card)
+			if score > bestScore {
+				bestScore, ties = score, 0
+			}
+			if score == bestScore {
+				ties++
+				if rng.IntN(ties) == 0 {
+					best = gamelogic.MoveData{Action: gamelogic.PlacementAction, Card: &gs.PlayerHands[playerIdx][cardIdx], Lane: &laneIdx}
+				}
+			}
+		}
+	}
+	return best
+}
+
+// Rough measure of how well the card keeps a wedge, square, column or skirmish possible
+func placementFit(side gamelogic.Deck, card gamelogic.Card) int {
+	if len(side) == 0 {
+		return card.Value
+	}
+	sameSuit, sameValue, straight := true, true, true
+	for _, c := range side {
+		sameSuit = sameSuit && c.Suit == card.Suit
+		sameValue = sameValue && c.Value == card.Value
+		diff := c.Value - card.Value
+		straight = straight && diff != 0 && diff <= 2 && diff >= -2
+	}
+	switch {
+	case sameSuit && straight:
+		return 50 + card.Value
+	case sameValue:
+		return 40 + card.Value
+	case sameSuit:
+		return 30 + card.Value
+	case straight:
+		return 20 + card.Value
+	}
+	return card.Value
+}
+
+func init() {
+	for difficulty, name := range difficultyNames {
+		strategies["ismcts-"+name] = func(seed uint64) Strategy { return NewDifficultyBot(difficulty, seed) }
+	}
+}
@@ -0,0 +1,85 @@
+package bot
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/it-ankka/battleline/internal/gamelogic"
+)
+
+func TestDeterminizeMatchesView(t *testing.T) {
+	gs := gamelogic.NewGameStateWithSeed(3)
+	rng := rand.New(rand.NewPCG(3, 3))
+	random := NewRandomBot(3)
+
+	for !gs.IsOver() {
+		state := gs.GetPrivateGameState(gs.ActivePlayer)
+		determinized := Determinize(state, rng)
+
+		// The position parser rejects states that lose or duplicate cards
+		if _, err := gamelogic.ParsePosition(determinized.Position()); err != nil {
+			t.Fatalf("Determinize() gave an invalid state: %v", err)
+		}
+		view := determinized.GetPrivateGameState(gs.ActivePlayer)
+		if view.PlayerHand.String() != state.PlayerHand.String() ||
+			view.OpponentHandSize != state.OpponentHandSize ||
+			view.OpponentTacticsHandSize != state.OpponentTacticsHandSize ||
+			view.TroopDeckSize != state.TroopDeckSize ||
+			view.TacticsDeckSize != state.TacticsDeckSize {
+			t.Fatalf("Determinize() does not match the player's view in %s", gs.Position())
+		}
+
+		move := random.ChooseMove(state)
+		gs.ExecutePlayerMove(gs.ActivePlayer, &move)
+	}
+}
+
+func TestISMCTSPlaysLegalMoves(t *testing.T) {
+	gs := gamelogic.NewGameStateWithSeed(5)
+	players := [2]Strategy{NewISMCTSBot(Budget{Iterations: 30}, 5), NewRandomBot(5)}
+	players[0].(*ISMCTSBot).Workers = 3
+
+	for !gs.IsOver() {
+		move := players[gs.ActivePlayer].ChooseMove(gs.GetPrivateGameState(gs.ActivePlayer))
+		if err := gs.ValidatePlayerMove(gs.ActivePlayer, &move); err != nil {
+			t.Fatalf("ChooseMove() = %s: %v", move, err)
+		}
+		gs.ExecutePlayerMove(gs.ActivePlayer, &move)
+	}
+}
+
+func TestISMCTSZeroBudget(t *testing.T) {
+	bot := NewISMCTSBot(Budget{}, 1)
+	if bot.Budget != DifficultyEasy.Budget() {
+		t.Errorf("NewISMCTSBot() budget = %+v, want %+v", bot.Budget, DifficultyEasy.Budget())
+	}
+
+	// A budget cleared after construction must not search forever either
+	bot.Budget = Budget{}
+	gs := gamelogic.NewGameStateWithSeed(1)
+	move := bot.ChooseMove(gs.GetPrivateGameState(gs.ActivePlayer))
+	if err := gs.ValidatePlayerMove(gs.ActivePlayer, &move); err != nil {
+		t.Errorf("ChooseMove() = %s: %v", move, err)
+	}
+}
+
+func TestEndgameSolverFollowsDifficulty(t *testing.T) {
+	for seed := range uint64(20) {
+		gs := endgamePosition(seed)
+		if gs.IsOver() {
+			continue
+		}
+		state := gs.GetPrivateGameState(gs.ActivePlayer)
+		hard := NewDifficultyBot(DifficultyHard, seed)
+		if _, ok := hard.solveEndgame(state, hard.Budget.SolverNodes); !ok {
+			continue
+		}
+
+		easy := NewDifficultyBot(DifficultyEasy, seed)
+		if _, ok := easy.solveEndgame(state, easy.Budget.SolverNodes); ok {
+			t.Errorf("seed %d: the easy bot solved the endgame", seed)
+		}
+		return
+	}
+	t.Errorf("no endgame was solved by the hard bot")
+}
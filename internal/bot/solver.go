@@ -0,0 +1,169 @@
+package bot
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/it-ankka/battleline/internal/gamelogic"
+)
+
+var ErrSearchLimit = errors.New("Search limit reached.")
+
+// Exact result of a position where every card is known
+type Solution struct {
+	// Index of the player who wins with best play, or NoWinner when neither player can
+	Winner int
+	// Best moves for both players from the position to the end of the game
+	PrincipalVariation []gamelogic.MoveData
+	Nodes              int
+}
+
+// Result of a position with hidden cards, found by solving every way they can be dealt
+type DealSolution struct {
+	Move gamelogic.MoveData
+	// Share of the deals the move wins, with draws counting as half a win
+	Value float64
+	Deals int
+	Nodes int
+}
+
+type solver struct {
+	rootPlayer int
+	maxNodes   int
+	nodes      int
+}
+
+// Searches the whole game tree with alpha-beta pruning. Both hands and the order of
+// both decks are taken from the state, so it should only be used once they are known,
+// for example after both decks run out. Returns ErrSearchLimit if the position needs
+// more than maxNodes positions to solve.
+func Solve(gs *gamelogic.GameState, maxNodes int) (*Solution, error) {
+	root := gs.Clone()
+	root.Events = nil
+
+	s := &solver{rootPlayer: root.ActivePlayer, maxNodes: maxNodes}
+	value, pv, err := s.search(root, -1, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	winner := gamelogic.NoWinner
+	switch value {
+	case 1:
+		winner = s.rootPlayer
+	case -1:
+		winner = 1 - s.rootPlayer
+	}
+	return &Solution{Winner: winner, PrincipalVariation: pv, Nodes: s.nodes}, nil
+}
+
+// Solves every deal of the cards the active player cannot see and picks the legal move
+// with the best average result. Each deal is solved as if both players could see every
+// card, so the values are optimistic when more than one deal is left. Returns
+// ErrSearchLimit if there are more than maxDeals deals or the deals need more than
+// maxNodes positions in total.
+func SolveDeals(state *gamelogic.PrivateGameState, maxDeals int, maxNodes int) (*DealSolution, error) {
+	playerIdx := state.ActivePlayer
+	deals, ok := allDeals(state, playerIdx, maxDeals)
+	if !ok {
+		return nil, ErrSearchLimit
+	}
+
+	nodes := 0
+	best := &DealSolution{Value: -1, Deals: len(deals)}
+	for _, move := range state.LegalMoves {
+		total := 0.0
+		for _, deal := range deals {
+			child := deal.Clone()
+			child.ExecutePlayerMove(playerIdx, &move)
+			solution, err := Solve(child, maxNodes-nodes)
+			if err != nil {
+				return nil, err
+			}
+			nodes += solution.Nodes
+			total += outcome(solution.Winner, playerIdx)
+		}
+		if value := total / float64(len(deals)); value > best.Value {
+			best.Move, best.Value = move, value
+		}
+		if best.Value == 1 {
+			break
+		}
+	}
+	best.Nodes = nodes
+	return best, nil
+}
+
+// Value of the position for the root player: 1 for a win, 0 for a draw and -1 for a loss
+func (s *solver) search(gs *gamelogic.GameState, alpha int, beta int) (int, []gamelogic.MoveData, error) {
+	if gs.IsOver() {
+		switch gs.Winner() {
+		case s.rootPlayer:
+			return 1, nil, nil
+		case gamelogic.NoWinner:
+			return 0, nil, nil
+		default:
+			return -1, nil, nil
+		}
+	}
+
+	s.nodes++
+	if s.nodes > s.maxNodes {
+		return 0, nil, ErrSearchLimit
+	}
+
+	// A player keeps moving through the phases of their turn, so the side to
+	// maximize only changes when the active player does
+	maximizing := gs.ActivePlayer == s.rootPlayer
+	best := 2
+	if maximizing {
+		best = -2
+	}
+	var bestPV []gamelogic.MoveData
+
+	for _, move := range orderMoves(gs) {
+		child := gs.Clone()
+		child.ExecutePlayerMove(gs.ActivePlayer, &move)
+
+		value, pv, err := s.search(child, alpha, beta)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if (maximizing && value > best) || (!maximizing && value < best) {
+			best = value
+			bestPV = append([]gamelogic.MoveData{move}, pv...)
+		}
+		if maximizing {
+			alpha = max(alpha, best)
+		} else {
+			beta = min(beta, best)
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return best, bestPV, nil
+}
+
+// Tries claims first and troops that fit their lane best, which finds cutoffs sooner
+func orderMoves(gs *gamelogic.GameState) []gamelogic.MoveData {
+	moves := gs.LegalMoves(gs.ActivePlayer)
+	priority := func(move gamelogic.MoveData) int {
+		switch move.Action {
+		case gamelogic.ClaimAction:
+			return 1000
+		case gamelogic.PlacementAction:
+			if move.Lane == nil || move.Card.IsTactic() {
+				return 0
+			}
+			return placementFit(gs.Lanes[*move.Lane].Cards[gs.ActivePlayer], *move.Card)
+		default:
+			return 0
+		}
+	}
+	sort.SliceStable(moves, func(i, j int) bool {
+		return priority(moves[i]) > priority(moves[j])
+	})
+	return moves
+}
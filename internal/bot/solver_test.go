@@ -0,0 +1,198 @@
+package bot
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/it-ankka/battleline/internal/gamelogic"
+)
+
+// Plays random moves until both decks are empty
+func endgamePosition(seed uint64) *gamelogic.GameState {
+	gs := gamelogic.NewGameStateWithSeed(seed)
+	rng := rand.New(rand.NewPCG(seed, seed))
+	for !gs.IsOver() && gs.HasDrawableCards() {
+		moves := gs.LegalMoves(gs.ActivePlayer)
+		gs.ExecutePlayerMove(gs.ActivePlayer, &moves[rng.IntN(len(moves))])
+	}
+	return gs
+}
+
+func TestSolvePrincipalVariation(t *testing.T) {
+	solved := 0
+	for seed := range uint64(20) {
+		gs := endgamePosition(seed)
+		if gs.IsOver() {
+			continue
+		}
+
+		solution, err := Solve(gs, 200000)
+		if err == ErrSearchLimit {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Solve() error = %v", err)
+		}
+		solved++
+		t.Logf("seed %d solved in %d nodes", seed, solution.Nodes)
+
+		for _, move := range solution.PrincipalVariation {
+			if gs, _, err = gamelogic.Apply(gs, &move); err != nil {
+				t.Fatalf("principal variation move %s: %v", move, err)
+			}
+			// Every position on the line keeps the result of the whole game
+			if rest, err := Solve(gs, 200000); err == nil && rest.Winner != solution.Winner {
+				t.Errorf("seed %d: winner after %s = %d, want %d", seed, move, rest.Winner, solution.Winner)
+			}
+		}
+		if !gs.IsOver() || gs.Winner() != solution.Winner {
+			t.Errorf("principal variation ends with winner %d, want %d", gs.Winner(), solution.Winner)
+		}
+	}
+	if solved == 0 {
+		t.Errorf("no endgame was solved")
+	}
+}
+
+// Minimax without pruning, or false if the position has more than limit nodes
+func bruteForceWinner(gs *gamelogic.GameState, rootPlayer int, limit *int) (int, bool) {
+	if gs.IsOver() {
+		switch gs.Winner() {
+		case rootPlayer:
+			return 1, true
+		case gamelogic.NoWinner:
+			return 0, true
+		}
+		return -1, true
+	}
+	if *limit--; *limit < 0 {
+		return 0, false
+	}
+
+	best := -2
+	if gs.ActivePlayer != rootPlayer {
+		best = 2
+	}
+	for _, move := range gs.LegalMoves(gs.ActivePlayer) {
+		child := gs.Clone()
+		child.ExecutePlayerMove(gs.ActivePlayer, &move)
+		value, ok := bruteForceWinner(child, rootPlayer, limit)
+		if !ok {
+			return 0, false
+		}
+		if gs.ActivePlayer == rootPlayer {
+			best = max(best, value)
+		} else {
+			best = min(best, value)
+		}
+	}
+	return best, true
+}
+
+func TestSolveMatchesMinimax(t *testing.T) {
+	compared := 0
+	for seed := range uint64(40) {
+		gs := endgamePosition(seed)
+		if gs.IsOver() {
+			continue
+		}
+		limit := 3000
+		value, ok := bruteForceWinner(gs, gs.ActivePlayer, &limit)
+		if !ok {
+			continue
+		}
+		compared++
+
+		want := map[int]int{1: gs.ActivePlayer, 0: gamelogic.NoWinner, -1: 1 - gs.ActivePlayer}[value]
+		solution, err := Solve(gs, 20000)
+		if err != nil {
+			t.Fatalf("Solve() error = %v", err)
+		}
+		if solution.Winner != want {
+			t.Errorf("seed %d: Solve() winner = %d, minimax winner = %d", seed, solution.Winner, want)
+		}
+	}
+	if compared == 0 {
+		t.Errorf("no endgame was small enough to compare")
+	}
+}
+
+// Plays random moves until only a few cards are left in the decks
+func lateGamePosition(seed uint64) *gamelogic.GameState {
+	gs := gamelogic.NewGameStateWithSeed(seed)
+	rng := rand.New(rand.NewPCG(seed, seed))
+	for !gs.IsOver() && (len(gs.TroopDeck) > 2 || len(gs.TacticsDeck) > 1 || gs.TurnPhase != gamelogic.PlacementPhase) {
+		moves := gs.LegalMoves(gs.ActivePlayer)
+		gs.ExecutePlayerMove(gs.ActivePlayer, &moves[rng.IntN(len(moves))])
+	}
+	return gs
+}
+
+func TestAllDeals(t *testing.T) {
+	checked := 0
+	for seed := range uint64(10) {
+		gs := lateGamePosition(seed)
+		if gs.IsOver() {
+			continue
+		}
+		checked++
+		state := gs.GetPrivateGameState(gs.ActivePlayer)
+		opponentIdx := 1 - gs.ActivePlayer
+
+		deals, ok := allDeals(state, gs.ActivePlayer, 100000)
+		if !ok {
+			t.Fatalf("seed %d: allDeals() found too many deals", seed)
+		}
+		key := func(deal *gamelogic.GameState) string {
+			return fmt.Sprint(gamelogic.NewCardSet(deal.PlayerHands[opponentIdx]), deal.TroopDeck, deal.TacticsDeck)
+		}
+		seen := map[string]bool{}
+		for _, deal := range deals {
+			if seen[key(deal)] {
+				t.Fatalf("seed %d: deal %s is dealt twice", seed, key(deal))
+			}
+			seen[key(deal)] = true
+		}
+		if !seen[key(gs)] {
+			t.Errorf("seed %d: the real deal is missing from %d deals", seed, len(deals))
+		}
+
+		if _, ok := allDeals(state, gs.ActivePlayer, len(deals)-1); ok && len(deals) > 1 {
+			t.Errorf("seed %d: allDeals() ignored the limit of %d deals", seed, len(deals)-1)
+		}
+	}
+	if checked == 0 {
+		t.Errorf("no position was checked")
+	}
+}
+
+func TestSolveDealsMatchesSolve(t *testing.T) {
+	compared := 0
+	for seed := range uint64(20) {
+		gs := endgamePosition(seed)
+		if gs.IsOver() {
+			continue
+		}
+		solution, err := Solve(gs, 200000)
+		if err == ErrSearchLimit {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Solve() error = %v", err)
+		}
+		compared++
+
+		// Both decks are empty, so there is only one deal
+		dealSolution, err := SolveDeals(gs.GetPrivateGameState(gs.ActivePlayer), 1, 1000000)
+		if err != nil {
+			t.Fatalf("seed %d: SolveDeals() error = %v", seed, err)
+		}
+		if want := outcome(solution.Winner, gs.ActivePlayer); dealSolution.Value != want || dealSolution.Deals != 1 {
+			t.Errorf("seed %d: SolveDeals() = %.1f over %d deals, want %.1f over 1", seed, dealSolution.Value, dealSolution.Deals, want)
+		}
+	}
+	if compared == 0 {
+		t.Errorf("no endgame was solved")
+	}
+}
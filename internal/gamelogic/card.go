@@ -31,15 +31,86 @@ func (s Suit) String() string {
 	return suitName[s]
 }
 
+type Tactic int
+
+const (
+	TacticNone Tactic = iota
+	TacticAlexander
+	TacticDarius
+	TacticCompanionCavalry
+	TacticShieldBearers
+	TacticFog
+	TacticMud
+	TacticScout
+	TacticRedeploy
+	TacticDeserter
+	TacticTraitor
+)
+
+var Tactics = []Tactic{
+	TacticAlexander,
+	TacticDarius,
+	TacticCompanionCavalry,
+	TacticShieldBearers,
+	TacticFog,
+	TacticMud,
+	TacticScout,
+	TacticRedeploy,
+	TacticDeserter,
+	TacticTraitor,
+}
+
+var tacticName = map[Tactic]string{
+	TacticNone:             "none",
+	TacticAlexander:        "alexander",
+	TacticDarius:           "darius",
+	TacticCompanionCavalry: "companion cavalry",
+	TacticShieldBearers:    "shield bearers",
+	TacticFog:              "fog",
+	TacticMud:              "mud",
+	TacticScout:            "scout",
+	TacticRedeploy:         "redeploy",
+	TacticDeserter:         "deserter",
+	TacticTraitor:          "traitor",
+}
+
+func (t Tactic) String() string {
+	return tacticName[t]
+}
+
+// Troop cards have Tactic set to TacticNone. Tactics cards ignore Suit and Value.
 type Card struct {
-	Suit  Suit `json:"suit"`
-	Value int  `json:"value"`
+	Suit   Suit   `json:"suit"`
+	Value  int    `json:"value"`
+	Tactic Tactic `json:"tactic,omitempty"`
+}
+
+func (c Card) IsTactic() bool {
+	return c.Tactic != TacticNone
+}
+
+func (c Card) IsEnvironment() bool {
+	return c.Tactic == TacticFog || c.Tactic == TacticMud
+}
+
+func (c Card) IsGuile() bool {
+	switch c.Tactic {
+	case TacticScout, TacticRedeploy, TacticDeserter, TacticTraitor:
+		return true
+	}
+	return false
 }
 
 func (c Card) String() string {
+	if c.IsTactic() {
+		return strings.ToUpper(c.Tactic.String())
+	}
 	return fmt.Sprintf("%s %d", strings.ToUpper(c.Suit.String())[:1], c.Value)
 }
 
 func (c Card) SuitSortingValue() int {
+	if c.IsTactic() {
+		return len(Suits)*100 + int(c.Tactic)
+	}
 	return int(c.Suit)*100 + (c.Value - 1)
 }
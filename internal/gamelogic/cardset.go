@@ -0,0 +1,149 @@
+package gamelogic
+
+import "math/bits"
+
+// Compact set of troop cards where bit suit*10 + value-1 marks each card.
+// Tactics cards cannot be stored in a CardSet.
+type CardSet uint64
+
+const (
+	EmptyCardSet CardSet = 0
+	FullCardSet  CardSet = 1<<60 - 1
+)
+
+var (
+	suitMasks  [6]CardSet
+	valueMasks [11]CardSet
+)
+
+func init() {
+	for _, s := range Suits {
+		for v := 1; v <= 10; v++ {
+			bit := CardSetOf(Card{Suit: s, Value: v})
+			suitMasks[s] |= bit
+			valueMasks[v] |= bit
+		}
+	}
+}
+
+func CardSetOf(c Card) CardSet {
+	if c.IsTactic() || c.Value < 1 || c.Value > 10 {
+		return EmptyCardSet
+	}
+	return 1 << (int(c.Suit)*10 + c.Value - 1)
+}
+
+// Converts the troop cards of the deck into a set, ignoring any tactics cards
+func NewCardSet(deck Deck) CardSet {
+	s := EmptyCardSet
+	for _, c := range deck {
+		s |= CardSetOf(c)
+	}
+	return s
+}
+
+func (s CardSet) Has(c Card) bool {
+	bit := CardSetOf(c)
+	return bit != EmptyCardSet && s&bit != 0
+}
+
+func (s CardSet) Add(c Card) CardSet {
+	return s | CardSetOf(c)
+}
+
+func (s CardSet) Remove(c Card) CardSet {
+	return s &^ CardSetOf(c)
+}
+
+func (s CardSet) Union(other CardSet) CardSet {
+	return s | other
+}
+
+func (s CardSet) Intersect(other CardSet) CardSet {
+	return s & other
+}
+
+func (s CardSet) Difference(other CardSet) CardSet {
+	return s &^ other
+}
+
+func (s CardSet) Count() int {
+	return bits.OnesCount64(uint64(s))
+}
+
+func (s CardSet) IsEmpty() bool {
+	return s == EmptyCardSet
+}
+
+func (s CardSet) OfSuit(suit Suit) CardSet {
+	return s & suitMasks[suit]
+}
+
+func (s CardSet) OfValue(value int) CardSet {
+	if value < 1 || value > 10 {
+		return EmptyCardSet
+	}
+	return s & valueMasks[value]
+}
+
+// Converts the set into a deck sorted by suit and value
+func (s CardSet) Deck() Deck {
+	deck := make(Deck, 0, s.Count())
+	for s != EmptyCardSet {
+		idx := bits.TrailingZeros64(uint64(s))
+		deck = append(deck, Card{Suit: Suit(idx / 10), Value: idx%10 + 1})
+		s &= s - 1
+	}
+	return deck
+}
+
+// Bitmask of the card values in the set, where bit v marks value v
+func (s CardSet) Values() uint16 {
+	var values uint16
+	for suit := range suitMasks {
+		values |= uint16(s>>(suit*10)) & 0x3ff
+	}
+	return values << 1
+}
+
+func (s CardSet) IsAllSameSuit() bool {
+	for _, mask := range suitMasks {
+		if s&^mask == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s CardSet) IsAllSameValue() bool {
+	return bits.OnesCount16(s.Values()) <= 1
+}
+
+// Each card has a different value and the values are consecutive
+func (s CardSet) IsStraight() bool {
+	return isConsecutive(s.Values()) && bits.OnesCount16(s.Values()) == s.Count()
+}
+
+func (s CardSet) GetFormation() Formation {
+	sameSuit, sameValue, straight := s.IsAllSameSuit(), s.IsAllSameValue(), s.IsStraight()
+	if s.Count() < 3 {
+		return FormationNone
+	} else if sameSuit && straight {
+		return FormationWedge
+	} else if sameValue {
+		return FormationSquare
+	} else if sameSuit {
+		return FormationColumn
+	} else if straight {
+		return FormationSkirmish
+	}
+	return FormationFray
+}
+
+func isConsecutive(values uint16) bool {
+	if values == 0 {
+		return true
+	}
+	shifted := values >> bits.TrailingZeros16(values)
+	return shifted&(shifted+1) == 0
+}
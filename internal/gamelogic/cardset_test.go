@@ -0,0 +1,90 @@
+package gamelogic
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestCardSetDeckRoundTrip(t *testing.T) {
+	deck := CreateTroopDeck()
+	set := NewCardSet(deck)
+
+	if set != FullCardSet || set.Count() != len(deck) {
+		t.Fatalf("NewCardSet(troop deck) = %x with %d cards", uint64(set), set.Count())
+	}
+	for i, c := range set.Deck() {
+		if c != deck[i] {
+			t.Errorf("Deck()[%d] = %s, want %s", i, c, deck[i])
+		}
+	}
+	if NewCardSet(CreateTacticsDeck()) != EmptyCardSet {
+		t.Errorf("tactics cards should not be added to a card set")
+	}
+}
+
+func TestCardSetOperations(t *testing.T) {
+	red5, blue7 := troop(SuitRed, 5), troop(SuitBlue, 7)
+	set := EmptyCardSet.Add(red5).Add(blue7)
+
+	if !set.Has(red5) || !set.Has(blue7) || set.Count() != 2 {
+		t.Errorf("Add() did not add both cards")
+	}
+	if set.Remove(red5).Has(red5) {
+		t.Errorf("Remove() did not remove the card")
+	}
+	if set.OfSuit(SuitBlue) != CardSetOf(blue7) || set.OfValue(5) != CardSetOf(red5) {
+		t.Errorf("OfSuit() or OfValue() returned the wrong cards")
+	}
+	if set.Difference(CardSetOf(red5)).Union(CardSetOf(red5)) != set {
+		t.Errorf("Difference() and Union() do not round trip")
+	}
+}
+
+// Formation of a troop deck worked out card by card
+func referenceFormation(d Deck) Formation {
+	sorted := d.SortByRank()
+	straight := true
+	for i := 1; i < len(sorted); i++ {
+		straight = straight && sorted[i].Value == sorted[i-1].Value+1
+	}
+	sameSuit, sameValue := d.IsAllSameSuit(), d.IsAllSameValue()
+	switch {
+	case sameSuit && straight:
+		return FormationWedge
+	case sameValue:
+		return FormationSquare
+	case sameSuit:
+		return FormationColumn
+	case straight:
+		return FormationSkirmish
+	}
+	return FormationFray
+}
+
+func TestCardSetFormationMatchesDeck(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+
+	for range 1000 {
+		size := MaxCardsPerSide + rng.IntN(2)
+		deck := CreateTroopDeck().Shuffle(rng)[:size]
+
+		want := referenceFormation(deck)
+		if got := NewCardSet(deck).GetFormation(); got != want {
+			t.Fatalf("CardSet(%s).GetFormation() = %s, want %s", deck, got, want)
+		}
+		if got := deck.GetFormation(); got != want {
+			t.Fatalf("Deck(%s).GetFormation() = %s, want %s", deck, got, want)
+		}
+	}
+}
+
+func TestFormationWithRepeatedWildcardCard(t *testing.T) {
+	// Companion cavalry is best played as a second red 8, which a card set cannot represent
+	deck := Deck{troop(SuitRed, 8), troop(SuitRed, 9), {Tactic: TacticCompanionCavalry}}
+	if got := deck.GetFormation(); got != FormationColumn {
+		t.Errorf("GetFormation() = %s, want %s", got, FormationColumn)
+	}
+	if got := deck.GetTotalValue(); got != int(FormationColumn)*100+25 {
+		t.Errorf("GetTotalValue() = %d, want %d", got, int(FormationColumn)*100+25)
+	}
+}
@@ -38,7 +38,7 @@ func (deck Deck) Pop() (Deck, Card) {
 
 func (deck Deck) FindCardIdx(card Card) int {
 	for i, c := range deck {
-		if c.Suit == card.Suit && c.Value == card.Value {
+		if c == card {
 			return i
 		}
 	}
@@ -57,11 +57,11 @@ func (deck Deck) RemoveAt(idx int) Deck {
 	return d
 }
 
-func (deck Deck) Shuffle() Deck {
+func (deck Deck) Shuffle(rng *rand.Rand) Deck {
 	d := deck.Copy()
 
 	for i := len(d) - 1; i > 0; i-- {
-		j := rand.IntN(i)
+		j := rng.IntN(i + 1)
 		d[j], d[i] = d[i], d[j]
 	}
 	return d
@@ -98,22 +98,30 @@ func CreateTroopDeck() Deck {
 	return deck
 }
 
-// Returns a integer where 100s are the formation value and the sum of the cards is the 10s and 1s
-func (deck Deck) GetBestPossibleTotalValue(bestFormationValue int, possibleCards Deck) int {
-	if len(deck) == MaxCardsPerSide || len(possibleCards) == 0 {
-		return max(deck.GetTotalValue(), bestFormationValue)
+func CreateTacticsDeck() Deck {
+	var deck = Deck{}
+	for _, t := range Tactics {
+		deck = append(deck, Card{Tactic: t})
 	}
+	return deck
+}
 
-	remainingPossible, nextCard := possibleCards.Pop()
-	formationWithCard := append(deck, nextCard).GetBestPossibleTotalValue(bestFormationValue, remainingPossible)
-	if formationWithCard > bestFormationValue {
-		bestFormationValue = formationWithCard
+func (deck Deck) Troops() Deck {
+	d := Deck{}
+	for _, c := range deck {
+		if !c.IsTactic() {
+			d = append(d, c)
+		}
 	}
+	return d
+}
 
-	formationWithoutCard := deck.GetBestPossibleTotalValue(bestFormationValue, remainingPossible)
-	if formationWithoutCard > bestFormationValue {
-		bestFormationValue = formationWithoutCard
+func (deck Deck) TacticsCount() int {
+	count := 0
+	for _, c := range deck {
+		if c.IsTactic() {
+			count++
+		}
 	}
-
-	return bestFormationValue
+	return count
 }
@@ -0,0 +1,108 @@
+package gamelogic
+
+import "fmt"
+
+type EventType string
+
+const (
+	DealEvent        EventType = "deal"
+	PlaceEvent       EventType = "place"
+	ClaimEvent       EventType = "claim"
+	EndClaimEvent    EventType = "end_claim"
+	DrawEvent        EventType = "draw"
+	ScoutReturnEvent EventType = "scout_return"
+	PassEvent        EventType = "pass"
+	EndEvent         EventType = "end"
+)
+
+var moveEventType = map[MoveAction]EventType{
+	PlacementAction:   PlaceEvent,
+	ClaimAction:       ClaimEvent,
+	EndClaimAction:    EndClaimEvent,
+	DrawAction:        DrawEvent,
+	ScoutReturnAction: ScoutReturnEvent,
+	PassAction:        PassEvent,
+}
+
+// Player is the starting player for deal events and the winner for end events.
+// Draw events record the drawn card so replays can be checked against the deal.
+type Event struct {
+	Type   EventType `json:"type"`
+	Turn   int       `json:"turn"`
+	Player int       `json:"player"`
+	Move   *MoveData `json:"move,omitempty"`
+	Card   *Card     `json:"card,omitempty"`
+	Seed   uint64    `json:"seed,omitempty,string"`
+}
+
+func newMoveEvent(turn int, playerIdx int, move *MoveData) (Event, bool) {
+	eventType, ok := moveEventType[move.Action]
+	if !ok {
+		return Event{}, false
+	}
+	return Event{Type: eventType, Turn: turn, Player: playerIdx, Move: move.Copy()}, true
+}
+
+func (move *MoveData) Copy() *MoveData {
+	m := &MoveData{Action: move.Action}
+	if move.Card != nil {
+		card := *move.Card
+		m.Card = &card
+	}
+	if move.Lane != nil {
+		lane := *move.Lane
+		m.Lane = &lane
+	}
+	if move.TacticsDeck != nil {
+		tacticsDeck := *move.TacticsDeck
+		m.TacticsDeck = &tacticsDeck
+	}
+	if move.TargetLane != nil {
+		targetLane := *move.TargetLane
+		m.TargetLane = &targetLane
+	}
+	if move.TargetCard != nil {
+		targetCard := *move.TargetCard
+		m.TargetCard = &targetCard
+	}
+	return m
+}
+
+// Rebuilds the game state from the seed by replaying the moves in the event log.
+// Passing only the first n events gives the state after the nth event.
+func Replay(seed uint64, events []Event) (*GameState, error) {
+	gs := NewGameStateWithSeed(seed)
+
+	for i, event := range events {
+		switch event.Type {
+		case DealEvent:
+			if i != 0 || event.Seed != seed || event.Player != gs.ActivePlayer {
+				return nil, fmt.Errorf("event %d: deal does not match seed %d", i, seed)
+			}
+			continue
+		case EndEvent:
+			if !gs.IsOver() || event.Player != gs.Winner() {
+				return nil, fmt.Errorf("event %d: game did not end with winner %d", i, event.Player)
+			}
+			continue
+		}
+
+		if event.Move == nil {
+			return nil, fmt.Errorf("event %d: %s event has no move", i, event.Type)
+		}
+		if event.Player != gs.ActivePlayer || !gs.IsValidPlayerMove(event.Player, event.Move) {
+			return nil, fmt.Errorf("event %d: invalid %s move for player %d", i, event.Move.Action, event.Player)
+		}
+
+		gs.ExecutePlayerMove(event.Player, event.Move)
+
+		if event.Type == DrawEvent && event.Card != nil {
+			hand := gs.PlayerHands[event.Player]
+			if hand[len(hand)-1] != *event.Card {
+				return nil, fmt.Errorf("event %d: drew %s instead of %s", i, hand[len(hand)-1], event.Card)
+			}
+		}
+	}
+
+	return gs, nil
+}
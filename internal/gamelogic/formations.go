@@ -44,24 +44,25 @@ func (d Deck) IsAllSameValue() bool {
 	return true
 }
 
+// A deck with a repeated card is never a straight, but a card set would hide the repeat
 func (d Deck) IsStraight() bool {
-	sortedDeck := d.SortByRank()
-
-	val := sortedDeck[0].Value
-	for _, c := range sortedDeck[1:] {
-		if val != c.Value-1 {
-			return false
-		}
-		val = c.Value
-	}
-	return true
+	set := NewCardSet(d)
+	return set.Count() == len(d) && set.IsStraight()
 }
 
 func (d Deck) GetFormation() Formation {
-	sameSuit, sameValue, straight := d.IsAllSameSuit(), d.IsAllSameValue(), d.IsStraight()
 	if len(d) < 3 {
 		return FormationNone
-	} else if sameSuit && straight {
+	}
+	if d.HasWildcards() {
+		return d.BestResolution().GetFormation()
+	}
+	// A wildcard can stand in for a card that is already on the side, which a card set cannot hold
+	if set := NewCardSet(d); set.Count() == len(d) {
+		return set.GetFormation()
+	}
+	sameSuit, sameValue, straight := d.IsAllSameSuit(), d.IsAllSameValue(), d.IsStraight()
+	if sameSuit && straight {
 		return FormationWedge
 	} else if sameValue {
 		return FormationSquare
@@ -74,9 +75,114 @@ func (d Deck) GetFormation() Formation {
 }
 
 func (d Deck) GetTotalValue() int {
+	if d.HasWildcards() {
+		return d.BestResolution().GetTotalValue()
+	}
 	totalValue := int(d.GetFormation()) * 100
 	for _, card := range d {
 		totalValue += card.Value
 	}
 	return totalValue
 }
+
+// Sum of the card values with every wildcard at its highest possible value
+func (d Deck) GetSumValue() int {
+	sum := 0
+	for _, card := range d {
+		if !card.IsWildcard() {
+			sum += card.Value
+			continue
+		}
+		highest := 0
+		for _, option := range card.WildcardOptions() {
+			highest = max(highest, option.Value)
+		}
+		sum += highest
+	}
+	return sum
+}
+
+// Fog ignores formations, so the lane is decided on the sum of the cards only
+func (d Deck) GetLaneValue(fog bool) int {
+	if fog {
+		return d.GetSumValue()
+	}
+	return d.GetTotalValue()
+}
+
+// Returns every troop card the given morale tactics card can stand in for
+func (c Card) WildcardOptions() []Card {
+	options := []Card{}
+	for _, s := range Suits {
+		switch c.Tactic {
+		case TacticAlexander, TacticDarius:
+			for v := 1; v <= 10; v++ {
+				options = append(options, Card{Suit: s, Value: v})
+			}
+		case TacticCompanionCavalry:
+			options = append(options, Card{Suit: s, Value: 8})
+		case TacticShieldBearers:
+			for v := 1; v <= 3; v++ {
+				options = append(options, Card{Suit: s, Value: v})
+			}
+		}
+	}
+	return options
+}
+
+func (c Card) IsWildcard() bool {
+	switch c.Tactic {
+	case TacticAlexander, TacticDarius, TacticCompanionCavalry, TacticShieldBearers:
+		return true
+	}
+	return false
+}
+
+func (c Card) IsLeader() bool {
+	return c.Tactic == TacticAlexander || c.Tactic == TacticDarius
+}
+
+func (d Deck) HasWildcards() bool {
+	for _, c := range d {
+		if c.IsWildcard() {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolves every wildcard in the deck to the troop card that gives the strongest formation
+func (d Deck) BestResolution() Deck {
+	var best Deck
+	bestValue := -1
+	d.ForEachResolution(func(resolved Deck) {
+		if value := resolved.GetTotalValue(); value > bestValue {
+			bestValue = value
+			best = resolved.Copy()
+		}
+	})
+	return best
+}
+
+// Calls fn with every troop-only deck the wildcards in the deck can resolve to.
+// The deck passed to fn is reused between calls.
+func (d Deck) ForEachResolution(fn func(resolved Deck)) {
+	var resolve func(idx int, current Deck)
+	resolve = func(idx int, current Deck) {
+		if idx == len(current) {
+			fn(current)
+			return
+		}
+		if !current[idx].IsWildcard() {
+			resolve(idx+1, current)
+			return
+		}
+		wildcard := current[idx]
+		for _, option := range wildcard.WildcardOptions() {
+			current[idx] = option
+			resolve(idx+1, current)
+		}
+		current[idx] = wildcard
+	}
+	resolve(0, d.Copy())
+}
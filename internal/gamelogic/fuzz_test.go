@@ -0,0 +1,108 @@
+package gamelogic
+
+import (
+	"testing"
+)
+
+func FuzzParseMove(f *testing.F) {
+	for _, s := range []string{"R5>3", "O10>9", "SC", "RE:R5@2>7", "RE:R5@2", "DE:G7@4", "TR:G7@4>2", "C3", "E", "D", "DT", "^AL", "P"} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		move, err := ParseMove(s)
+		if err != nil {
+			return
+		}
+		again, err := ParseMove(move.String())
+		if err != nil {
+			t.Fatalf("ParseMove(%q) error = %v for the notation of %q", move.String(), err, s)
+		}
+		if again.String() != move.String() {
+			t.Fatalf("%q does not round trip: %q != %q", s, again.String(), move.String())
+		}
+	})
+}
+
+func FuzzParsePosition(f *testing.F) {
+	f.Add(NewGameStateWithSeed(1).Position())
+	f.Add(NewGameStateWithSeed(2).Position())
+
+	f.Fuzz(func(t *testing.T, s string) {
+		gs, err := ParsePosition(s)
+		if err != nil {
+			return
+		}
+		again, err := ParsePosition(gs.Position())
+		if err != nil {
+			t.Fatalf("ParsePosition(%q) error = %v", gs.Position(), err)
+		}
+		if again.Position() != gs.Position() {
+			t.Fatalf("%q does not round trip", s)
+		}
+		// Decoded positions may not be reachable, but the engine must still handle them
+		if !gs.IsOver() {
+			for _, move := range gs.LegalMoves(gs.ActivePlayer) {
+				if _, _, err := Apply(gs, &move); err != nil {
+					t.Fatalf("legal move %s rejected with %v", move, err)
+				}
+			}
+		}
+	})
+}
+
+// Each step is four bytes. Even steps play a legal move picked by the second byte and
+// odd steps build an arbitrary move from the remaining bytes, which Apply must either
+// reject or find among the legal moves.
+func FuzzApply(f *testing.F) {
+	f.Add(uint64(1), []byte{0, 0, 0, 0, 1, 0, 3, 4})
+	f.Add(uint64(2), []byte{1, 2, 255, 0, 1, 3, 9, 2, 0, 5, 0, 0})
+
+	actions := []MoveAction{PlacementAction, DrawAction, ClaimAction, EndClaimAction, PassAction, ScoutReturnAction}
+
+	f.Fuzz(func(t *testing.T, seed uint64, data []byte) {
+		gs := NewGameStateWithSeed(seed)
+
+		for ; len(data) >= 4 && !gs.IsOver(); data = data[4:] {
+			legalMoves := gs.LegalMoves(gs.ActivePlayer)
+			if data[0]%2 == 0 {
+				next, _, err := Apply(gs, &legalMoves[int(data[1])%len(legalMoves)])
+				if err != nil {
+					t.Fatalf("legal move rejected with %v", err)
+				}
+				gs = next
+				continue
+			}
+
+			lane := int(int8(data[2]))
+			hand := gs.PlayerHands[gs.ActivePlayer]
+			move := MoveData{
+				Action:      actions[int(data[1])%len(actions)],
+				Lane:        &lane,
+				TacticsDeck: boolPtr(data[3]%2 == 0),
+				TargetLane:  intPtr(int(data[3]) % 12),
+				TargetCard:  &Card{Suit: Suit(data[3] % 6), Value: int(data[3]%10) + 1},
+			}
+			if len(hand) > 0 {
+				move.Card = &hand[int(data[3])%len(hand)]
+			}
+			if data[0]%4 == 3 {
+				move.Lane = nil
+			}
+
+			next, _, err := Apply(gs, &move)
+			if err != nil {
+				continue
+			}
+			found := false
+			for _, legal := range legalMoves {
+				found = found || legal.String() == move.String()
+			}
+			if !found {
+				t.Fatalf("Apply accepted %s which is not a legal move", move.String())
+			}
+			gs = next
+		}
+		checkInvariants(t, gs)
+	})
+}
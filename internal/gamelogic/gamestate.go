@@ -10,41 +10,107 @@ const (
 	PlacementPhase TurnPhase = iota
 	ClaimPhase
 	DrawPhase
+	ScoutDrawPhase
+	ScoutReturnPhase
 )
 
 var phaseNames = map[TurnPhase]string{
-	PlacementPhase: "placement",
-	ClaimPhase:     "claim",
-	DrawPhase:      "draw",
+	PlacementPhase:   "placement",
+	ClaimPhase:       "claim",
+	DrawPhase:        "draw",
+	ScoutDrawPhase:   "scout_draw",
+	ScoutReturnPhase: "scout_return",
 }
 
+const (
+	MaxHandSize  = 7
+	ScoutDraws   = 3
+	ScoutReturns = 2
+
+	NoWinner             = -1
+	WinningFlags         = 5
+	WinningAdjacentFlags = 3
+)
+
 func (tp TurnPhase) String() string {
 	return phaseNames[tp]
 }
 
+func ParseTurnPhase(name string) (TurnPhase, bool) {
+	for phase, phaseName := range phaseNames {
+		if phaseName == name {
+			return phase, true
+		}
+	}
+	return PlacementPhase, false
+}
+
 type GameState struct {
-	ActivePlayer int
-	TurnPhase    TurnPhase
-	TroopDeck    Deck
-	Lanes        GameLanes
-	PlayerHands  [2]Deck
+	Seed          uint64
+	Turn          int
+	ActivePlayer  int
+	TurnPhase     TurnPhase
+	TroopDeck     Deck
+	TacticsDeck   Deck
+	DiscardPile   Deck
+	Lanes         GameLanes
+	PlayerHands   [2]Deck
+	TacticsPlayed [2]int
+
+	// Remaining steps of a Scout played this turn
+	ScoutDrawsLeft   int
+	ScoutReturnsLeft int
+
+	ConsecutivePasses int
+
+	// Append-only log of everything that has happened in the game
+	Events []Event
 }
 
 type PrivateGameState struct {
-	ActivePlayer     int       `json:"activePlayer"`
-	TurnPhase        string    `json:"turnPhase"`
-	Lanes            GameLanes `json:"lanes"`
-	PlayerHand       Deck      `json:"playerState"`
-	TroopDeckSize    int       `json:"drawDeckSize"`
-	OpponentHandSize int       `json:"opponentHandSize"`
+	ActivePlayer            int        `json:"activePlayer"`
+	TurnPhase               string     `json:"turnPhase"`
+	Lanes                   GameLanes  `json:"lanes"`
+	PlayerHand              Deck       `json:"playerState"`
+	TroopDeckSize           int        `json:"drawDeckSize"`
+	TacticsDeckSize         int        `json:"tacticsDeckSize"`
+	TacticsPlayed           [2]int     `json:"tacticsPlayed"`
+	DiscardPile             Deck       `json:"discardPile"`
+	ScoutDrawsLeft          int        `json:"scoutDrawsLeft"`
+	ScoutReturnsLeft        int        `json:"scoutReturnsLeft"`
+	ConsecutivePasses       int        `json:"consecutivePasses"`
+	OpponentHandSize        int        `json:"opponentHandSize"`
+	OpponentTacticsHandSize int        `json:"opponentTacticsHandSize"`
+	OpponentHand            Deck       `json:"opponentHand,omitempty"`
+	Winner                  int        `json:"winner"`
+	GameOver                bool       `json:"gameOver"`
+	Seed                    uint64     `json:"seed,omitempty,string"`
+	LegalMoves              []MoveData `json:"legalMoves"`
+	// Estimated chance of the player winning each lane, only set in training wheels mode
+	LaneWinProbabilities []float64 `json:"laneWinProbabilities,omitempty"`
 }
 
 func NewGameState() *GameState {
-	gs := &GameState{}
+	return NewGameStateWithSeed(rand.Uint64())
+}
+
+// The same seed always produces the same deal and starting player
+func NewGameStateWithSeed(seed uint64) *GameState {
+	gs := NewGameStateWithSource(rand.NewPCG(seed, seed))
+	gs.Seed = seed
+	gs.Events = append(gs.Events, Event{Type: DealEvent, Player: gs.ActivePlayer, Seed: seed})
+	return gs
+}
 
-	gs.ActivePlayer = rand.IntN(2)
+func NewGameStateWithSource(src rand.Source) *GameState {
+	rng := rand.New(src)
+	gs := &GameState{DiscardPile: Deck{}}
+
+	gs.ActivePlayer = rng.IntN(2)
 	gs.TroopDeck = CreateTroopDeck()
-	gs.TroopDeck = gs.TroopDeck.Shuffle()
+	gs.TroopDeck = gs.TroopDeck.Shuffle(rng)
+	gs.TacticsDeck = CreateTacticsDeck()
+	gs.TacticsDeck = gs.TacticsDeck.Shuffle(rng)
 
 	for range 7 {
 		for i := range gs.PlayerHands {
@@ -67,12 +133,142 @@ func (gs *GameState) GetPrivateGameState(playerIdx int) *PrivateGameState {
 		playerIdx = 1
 	}
 
+	// Only the active player has moves to make
+	legalMoves := []MoveData{}
+	if playerIdx == gs.ActivePlayer && !gs.IsOver() {
+		legalMoves = gs.LegalMoves(playerIdx)
+	}
+
 	return &PrivateGameState{
-		ActivePlayer:     gs.ActivePlayer,
-		TurnPhase:        gs.TurnPhase.String(),
-		Lanes:            gs.Lanes,
-		PlayerHand:       gs.PlayerHands[playerIdx],
-		TroopDeckSize:    len(gs.TroopDeck),
-		OpponentHandSize: len(gs.PlayerHands[opponentIdx]),
+		ActivePlayer:            gs.ActivePlayer,
+		TurnPhase:               gs.TurnPhase.String(),
+		Lanes:                   gs.Lanes,
+		PlayerHand:              gs.PlayerHands[playerIdx],
+		TroopDeckSize:           len(gs.TroopDeck),
+		TacticsDeckSize:         len(gs.TacticsDeck),
+		TacticsPlayed:           gs.TacticsPlayed,
+		DiscardPile:             gs.DiscardPile,
+		ScoutDrawsLeft:          gs.ScoutDrawsLeft,
+		ScoutReturnsLeft:        gs.ScoutReturnsLeft,
+		ConsecutivePasses:       gs.ConsecutivePasses,
+		OpponentHandSize:        len(gs.PlayerHands[opponentIdx]),
+		OpponentTacticsHandSize: gs.PlayerHands[opponentIdx].TacticsCount(),
+		Winner:                  gs.Winner(),
+		GameOver:                gs.IsOver(),
+		LegalMoves:              legalMoves,
+	}
+}
+
+// Final view of the game where the opponent's hand and the seed are revealed
+func (gs *GameState) GetRevealedGameState(playerIdx int) *PrivateGameState {
+	state := gs.GetPrivateGameState(playerIdx)
+	state.OpponentHand = gs.PlayerHands[1-playerIdx]
+	state.Seed = gs.Seed
+	return state
+}
+
+// Returns the index of the player who has claimed five flags or three adjacent flags, otherwise NoWinner
+func (gs *GameState) Winner() int {
+	for playerIdx, claim := range []int{ClaimedByPlayerOne, ClaimedByPlayerTwo} {
+		flags, adjacentFlags := 0, 0
+		for _, lane := range gs.Lanes {
+			if lane.Claimed != claim {
+				adjacentFlags = 0
+				continue
+			}
+			flags++
+			adjacentFlags++
+			if adjacentFlags >= WinningAdjacentFlags {
+				return playerIdx
+			}
+		}
+		if flags >= WinningFlags {
+			return playerIdx
+		}
+	}
+	return NoWinner
+}
+
+func (gs *GameState) PlayerHasLeaderOnBoard(playerIdx int) bool {
+	for _, lane := range gs.Lanes {
+		for _, c := range lane.Cards[playerIdx] {
+			if c.IsLeader() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// A player may not play a tactics card if they have already played more tactics cards than their opponent
+func (gs *GameState) PlayerCanPlayTactic(playerIdx int) bool {
+	opponentIdx := 1 - playerIdx
+	return gs.TacticsPlayed[playerIdx] <= gs.TacticsPlayed[opponentIdx]
+}
+
+// The game ends when a player wins or when neither player can move
+func (gs *GameState) IsOver() bool {
+	return gs.Winner() != NoWinner || gs.ConsecutivePasses >= len(gs.PlayerHands)
+}
+
+func (gs *GameState) HasDrawableCards() bool {
+	return len(gs.TroopDeck)+len(gs.TacticsDeck) > 0
+}
+
+func (gs *GameState) HasClaimableLanes() bool {
+	for _, lane := range gs.Lanes {
+		if lane.Claimed == NotClaimed && lane.Claimable {
+			return true
+		}
+	}
+	return false
+}
+
+// A turn runs through the placement, claim and draw phases. Playing a Scout adds the
+// scout draw and return phases after placement. The claim phase is skipped when no lane
+// can be claimed and the draw phase is skipped when the hand is already full or both
+// decks are empty.
+func (gs *GameState) AdvanceTurnPhase(playerIdx int) {
+	switch gs.TurnPhase {
+	case PlacementPhase, ScoutReturnPhase:
+		gs.TurnPhase = ClaimPhase
+		if gs.HasClaimableLanes() {
+			return
+		}
+		fallthrough
+	case ClaimPhase:
+		gs.TurnPhase = DrawPhase
+		if len(gs.PlayerHands[playerIdx]) < MaxHandSize && gs.HasDrawableCards() {
+			return
+		}
+		fallthrough
+	case DrawPhase:
+		gs.ActivePlayer = 1 - playerIdx
+		gs.TurnPhase = PlacementPhase
+		gs.Turn += 1
+	case ScoutDrawPhase:
+		gs.TurnPhase = ScoutReturnPhase
+	}
+}
+
+// Deep copy of the state that shares no decks with the original. Only the
+// append-only event history is shared.
+func (gs *GameState) Clone() *GameState {
+	clone := *gs
+	clone.TroopDeck = gs.TroopDeck.Copy()
+	clone.TacticsDeck = gs.TacticsDeck.Copy()
+	clone.DiscardPile = gs.DiscardPile.Copy()
+	for i := range gs.PlayerHands {
+		clone.PlayerHands[i] = gs.PlayerHands[i].Copy()
+	}
+	for i := range gs.Lanes {
+		for j := range gs.Lanes[i].Cards {
+			clone.Lanes[i].Cards[j] = gs.Lanes[i].Cards[j].Copy()
+			clone.Lanes[i].Environment[j] = gs.Lanes[i].Environment[j].Copy()
+		}
 	}
+	// Events are never changed once logged, so the history is shared. Capping the
+	// capacity makes the first append copy it instead of writing into the original.
+	clone.Events = gs.Events[:len(gs.Events):len(gs.Events)]
+	return &clone
 }
@@ -0,0 +1,141 @@
+package gamelogic
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+// Every move the validator could be asked about in the current phase, including
+// lanes and targets that are out of range
+func candidateMoves(gs *GameState, playerIdx int) []MoveData {
+	lanes := []*int{nil}
+	for laneIdx := -1; laneIdx <= len(gs.Lanes); laneIdx++ {
+		lanes = append(lanes, intPtr(laneIdx))
+	}
+
+	type target struct {
+		lane int
+		card Card
+	}
+	targets := []target{{len(gs.Lanes), troop(SuitRed, 1)}, {-1, troop(SuitRed, 1)}}
+	for laneIdx, lane := range gs.Lanes {
+		for _, side := range lane.Cards {
+			for _, c := range side {
+				targets = append(targets, target{laneIdx, c})
+			}
+		}
+	}
+
+	moves := []MoveData{
+		{Action: EndClaimAction},
+		{Action: PassAction},
+		{Action: DrawAction, TacticsDeck: boolPtr(false)},
+		{Action: DrawAction, TacticsDeck: boolPtr(true)},
+		{Action: "unknown"},
+	}
+	for _, lane := range lanes {
+		moves = append(moves, MoveData{Action: ClaimAction, Lane: lane})
+	}
+
+	for _, c := range append(gs.PlayerHands[playerIdx].Copy(), gs.PlayerHands[1-playerIdx]...) {
+		moves = append(moves, MoveData{Action: ScoutReturnAction, Card: &c})
+		for _, lane := range lanes {
+			moves = append(moves, MoveData{Action: PlacementAction, Card: &c, Lane: lane})
+			if !c.IsGuile() {
+				continue
+			}
+			for _, t := range targets {
+				moves = append(moves, MoveData{Action: PlacementAction, Card: &c, Lane: lane, TargetLane: intPtr(t.lane), TargetCard: &t.card})
+			}
+		}
+	}
+	return moves
+}
+
+func checkInvariants(t *testing.T, gs *GameState) {
+	t.Helper()
+
+	// The position check covers card conservation across decks, hands and lanes
+	if err := gs.validatePosition(); err != nil {
+		t.Fatalf("%v in %s", err, gs.Position())
+	}
+	for i := range gs.Lanes {
+		for _, side := range gs.Lanes[i].Cards {
+			if len(side) > gs.Lanes[i].MaxCards() {
+				t.Fatalf("lane %d has %d cards on a side in %s", i+1, len(side), gs.Position())
+			}
+		}
+	}
+
+	if gs.IsOver() {
+		return
+	}
+	legal := map[string]bool{}
+	for _, move := range gs.LegalMoves(gs.ActivePlayer) {
+		legal[move.String()] = true
+		if err := gs.ValidatePlayerMove(gs.ActivePlayer, &move); err != nil {
+			t.Fatalf("legal move %s rejected with %v in %s", move.String(), err, gs.Position())
+		}
+	}
+	for _, move := range candidateMoves(gs, gs.ActivePlayer) {
+		if gs.IsValidPlayerMove(gs.ActivePlayer, &move) && !legal[move.String()] {
+			t.Fatalf("valid move %s missing from LegalMoves in %s", move.String(), gs.Position())
+		}
+	}
+}
+
+// With every unplayed troop card known, the opponent cannot beat a claimed lane
+func checkClaim(t *testing.T, gs *GameState, laneIdx int) {
+	t.Helper()
+
+	lane := &gs.Lanes[laneIdx]
+	playerIdx := lane.Claimed - 1
+	opponentIdx := 1 - playerIdx
+	unplayed := append(gs.TroopDeck.Copy(), gs.PlayerHands[0]...)
+	unplayed = append(unplayed, gs.PlayerHands[1]...)
+
+	playerValue := lane.SideValue(playerIdx)
+	opponentValue := bruteForceBestPossibleValue(lane.Cards[opponentIdx], unplayed, lane.MaxCards(), lane.Fog)
+	if opponentValue > playerValue || (opponentValue == playerValue && !lane.PlayerCompletedFirst(playerIdx)) {
+		t.Fatalf("lane %d claimed with %d but the opponent can reach %d in %s", laneIdx+1, playerValue, opponentValue, gs.Position())
+	}
+}
+
+func TestSelfPlayInvariants(t *testing.T) {
+	games := 100
+	if testing.Short() {
+		games = 10
+	}
+
+	for seed := range uint64(games) {
+		gs := NewGameStateWithSeed(seed)
+		rng := rand.New(rand.NewPCG(seed, 0))
+
+		for !gs.IsOver() {
+			checkInvariants(t, gs)
+
+			moves := gs.LegalMoves(gs.ActivePlayer)
+			move := moves[rng.IntN(len(moves))]
+			gs.ExecutePlayerMove(gs.ActivePlayer, &move)
+
+			if move.Action == ClaimAction {
+				checkClaim(t, gs, *move.Lane)
+			}
+		}
+		checkInvariants(t, gs)
+	}
+}
+
+func TestOutOfRangeLanes(t *testing.T) {
+	gs := NewGameStateWithSeed(1)
+
+	for _, laneIdx := range []int{-1, len(gs.Lanes), 100} {
+		if gs.PlayerCanClaimLane(0, laneIdx) || gs.Lanes.PlayerCanPlaceInLane(0, laneIdx) {
+			t.Errorf("lane %d should be rejected", laneIdx)
+		}
+		move := &MoveData{Action: PlacementAction, Card: &gs.PlayerHands[gs.ActivePlayer][0], Lane: intPtr(laneIdx)}
+		if _, _, err := Apply(gs, move); err != ErrLaneUnavailable {
+			t.Errorf("Apply() to lane %d error = %v, want %v", laneIdx, err, ErrLaneUnavailable)
+		}
+	}
+}
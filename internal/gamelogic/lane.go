@@ -1,26 +1,91 @@
 package gamelogic
 
-const MaxCardsPerSide = 3
+const (
+	MaxCardsPerSide    = 3
+	MudMaxCardsPerSide = 4
+)
+
 const (
 	NotClaimed = iota
 	ClaimedByPlayerOne
 	ClaimedByPlayerTwo
 )
 
+const (
+	NotCompleted = iota
+	CompletedByPlayerOne
+	CompletedByPlayerTwo
+)
+
 type Lane struct {
 	Cards     [2]Deck `json:"cards"`
 	Claimed   int     `json:"claimed"`
 	Claimable bool    `json:"claimable"`
+	// Fog and Mud cards stay on the lane, on the side of the player who played them.
+	// The flags are kept in sync with them by UpdateEnvironment.
+	Environment [2]Deck `json:"environment"`
+	Fog         bool    `json:"fog"`
+	Mud         bool    `json:"mud"`
+	// The player who completed their side first wins ties
+	CompletedFirst int `json:"completedFirst"`
 }
 
 type GameLanes [9]Lane
 
+func (lane *Lane) MaxCards() int {
+	if lane.Mud {
+		return MudMaxCardsPerSide
+	}
+	return MaxCardsPerSide
+}
+
+func (lane *Lane) UpdateEnvironment() {
+	lane.Fog, lane.Mud = false, false
+	for _, side := range lane.Environment {
+		for _, c := range side {
+			lane.Fog = lane.Fog || c.Tactic == TacticFog
+			lane.Mud = lane.Mud || c.Tactic == TacticMud
+		}
+	}
+}
+
+func (lane *Lane) IsSideComplete(playerIdx int) bool {
+	return len(lane.Cards[playerIdx]) >= lane.MaxCards()
+}
+
+func (lane *Lane) PlayerCompletedFirst(playerIdx int) bool {
+	return lane.CompletedFirst == playerIdx+1
+}
+
+// Keeps track of which side was completed first. Cards removed by guile tactics
+// or the extra card required by Mud can make a completed side incomplete again.
+func (lane *Lane) UpdateCompletion(playerIdx int) {
+	if lane.CompletedFirst != NotCompleted && !lane.IsSideComplete(lane.CompletedFirst-1) {
+		lane.CompletedFirst = NotCompleted
+	}
+	if lane.CompletedFirst != NotCompleted {
+		return
+	}
+	// The player making the move is the last one to complete their side
+	for _, idx := range []int{1 - playerIdx, playerIdx} {
+		if lane.IsSideComplete(idx) {
+			lane.CompletedFirst = idx + 1
+			return
+		}
+	}
+}
+
+func (lane *Lane) SideValue(playerIdx int) int {
+	return lane.Cards[playerIdx].GetLaneValue(lane.Fog)
+}
+
 func (gameState *GameState) UpdateClaimableLanes(playerIdx int) {
 	opponentIdx := 1 - playerIdx
 
-	unplayedCards := gameState.TroopDeck.Copy()
-	unplayedCards = append(unplayedCards, gameState.PlayerHands[0]...)
-	unplayedCards = append(unplayedCards, gameState.PlayerHands[1]...)
+	// Unplayed tactics cards are not considered when proving a claim
+	unplayedCards := NewCardSet(gameState.TroopDeck).
+		Union(NewCardSet(gameState.PlayerHands[0])).
+		Union(NewCardSet(gameState.PlayerHands[1]))
 
 	for i := range gameState.Lanes {
 		lane := &gameState.Lanes[i]
@@ -30,36 +95,96 @@ func (gameState *GameState) UpdateClaimableLanes(playerIdx int) {
 			continue
 		}
 
-		playerCards := lane.Cards[playerIdx]
 		opponentCards := lane.Cards[opponentIdx]
 
-		playerSideComplete := len(playerCards) >= MaxCardsPerSide
-		if !playerSideComplete {
+		if !lane.IsSideComplete(playerIdx) {
 			lane.Claimable = false
 			continue
 		}
 
-		opponentSideComplete := len(opponentCards) >= MaxCardsPerSide
-		if opponentSideComplete {
-			lane.Claimable = playerCards.GetTotalValue() > opponentCards.GetTotalValue()
-			continue
+		playerValue := lane.SideValue(playerIdx)
+		opponentValue := 0
+		if lane.IsSideComplete(opponentIdx) {
+			opponentValue = lane.SideValue(opponentIdx)
+		} else {
+			opponentValue = opponentCards.BestPossibleValue(unplayedCards, lane.MaxCards(), lane.Fog)
 		}
 
-		lane.Claimable = opponentCards.GetBestPossibleTotalValue(0, unplayedCards) < playerCards.GetTotalValue()
+		lane.Claimable = playerValue > opponentValue || (playerValue == opponentValue && lane.PlayerCompletedFirst(playerIdx))
 	}
 }
 
 func (gameState *GameState) PlayerCanClaimLane(playerIdx int, laneIdx int) bool {
-	if (laneIdx < 0 && laneIdx > len(gameState.Lanes)-1) || gameState.Lanes[laneIdx].Claimed != NotClaimed {
+	if !gameState.Lanes.IsUnclaimedLane(laneIdx) {
 		return false
 	}
 	return gameState.Lanes[laneIdx].Claimable
 }
 
 func (lanes *GameLanes) PlayerCanPlaceInLane(playerIdx int, laneIdx int) bool {
-	if (laneIdx < 0 && laneIdx > len(lanes)-1) || lanes[laneIdx].Claimed != NotClaimed {
+	if !lanes.IsUnclaimedLane(laneIdx) {
 		return false
 	}
 	cardsCount := len(lanes[laneIdx].Cards[playerIdx])
-	return cardsCount < MaxCardsPerSide
+	return cardsCount < lanes[laneIdx].MaxCards()
+}
+
+func (lanes *GameLanes) IsUnclaimedLane(laneIdx int) bool {
+	return laneIdx >= 0 && laneIdx < len(lanes) && lanes[laneIdx].Claimed == NotClaimed
+}
+
+func (lanes *GameLanes) RemoveCard(laneIdx int, playerIdx int, card Card) Card {
+	lane := &lanes[laneIdx]
+	cardIdx := lane.Cards[playerIdx].FindCardIdx(card)
+	lane.Cards[playerIdx] = lane.Cards[playerIdx].RemoveAt(cardIdx)
+	return card
+}
+
+// Removes a troop or tactics card from the player's side of the lane
+func (lanes *GameLanes) RemoveAnyCard(laneIdx int, playerIdx int, card Card) Card {
+	if !card.IsEnvironment() {
+		return lanes.RemoveCard(laneIdx, playerIdx, card)
+	}
+	lane := &lanes[laneIdx]
+	cardIdx := lane.Environment[playerIdx].FindCardIdx(card)
+	lane.Environment[playerIdx] = lane.Environment[playerIdx].RemoveAt(cardIdx)
+	lane.UpdateEnvironment()
+	return card
+}
+
+// Whether a guile tactic can take the card from the player's side of the lane. Mud
+// cannot be taken while a side holds four cards, since it would exceed the normal limit.
+func (lane *Lane) CanTakeCard(playerIdx int, card Card) bool {
+	if !card.IsEnvironment() {
+		return lane.Cards[playerIdx].FindCardIdx(card) != -1
+	}
+	if lane.Environment[playerIdx].FindCardIdx(card) == -1 {
+		return false
+	}
+	return card.Tactic != TacticMud || (len(lane.Cards[0]) <= MaxCardsPerSide && len(lane.Cards[1]) <= MaxCardsPerSide)
+}
+
+// Cards a guile tactic can take from the player's side of the lane
+func (lane *Lane) TakeableCards(playerIdx int) Deck {
+	cards := Deck{}
+	for _, c := range append(lane.Cards[playerIdx].Copy(), lane.Environment[playerIdx]...) {
+		if lane.CanTakeCard(playerIdx, c) {
+			cards = append(cards, c)
+		}
+	}
+	return cards
+}
+
+func (lanes *GameLanes) PlayerCanModifyLane(laneIdx int, tactic Tactic) bool {
+	if !lanes.IsUnclaimedLane(laneIdx) {
+		return false
+	}
+	switch tactic {
+	case TacticFog:
+		return !lanes[laneIdx].Fog
+	case TacticMud:
+		return !lanes[laneIdx].Mud
+	default:
+		return false
+	}
 }
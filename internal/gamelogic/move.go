@@ -1,78 +1,554 @@
 package gamelogic
 
+import "errors"
+
 type MoveAction string
 
 const (
-	PlacementAction MoveAction = "placement"
-	DrawAction      MoveAction = "draw"
-	ClaimAction     MoveAction = "claim"
+	PlacementAction   MoveAction = "placement"
+	DrawAction        MoveAction = "draw"
+	ClaimAction       MoveAction = "claim"
+	EndClaimAction    MoveAction = "end_claim"
+	PassAction        MoveAction = "pass"
+	ScoutReturnAction MoveAction = "scout_return"
 )
 
+// Guile tactics are played with PlacementAction. Redeploy, Deserter and Traitor
+// pick a card on the board with TargetLane and TargetCard. Redeploy and Deserter
+// can also pick a Fog or Mud card. Redeploy and Traitor move the card to Lane, and
+// a Redeploy without a Lane discards the card.
+// Scout is followed by DrawAction and ScoutReturnAction steps.
 type MoveData struct {
 	Action      MoveAction `json:"action"`
 	Card        *Card      `json:"card"`
 	Lane        *int       `json:"lane"`
 	TacticsDeck *bool      `json:"tacticsDeck"`
+	TargetLane  *int       `json:"targetLane"`
+	TargetCard  *Card      `json:"targetCard"`
 }
 
+var (
+	ErrGameOver         = errors.New("Game is over.")
+	ErrNotActivePlayer  = errors.New("Not the active player.")
+	ErrUnknownAction    = errors.New("Unknown move action.")
+	ErrWrongPhase       = errors.New("Move is not allowed in this turn phase.")
+	ErrMissingMoveData  = errors.New("Move is missing required data.")
+	ErrCardNotInHand    = errors.New("Card is not in hand.")
+	ErrTacticsLimit     = errors.New("Cannot play more tactics cards than the opponent.")
+	ErrLeaderOnBoard    = errors.New("A leader is already on the board.")
+	ErrLaneUnavailable  = errors.New("Lane cannot be played to.")
+	ErrLaneNotClaimable = errors.New("Lane cannot be claimed.")
+	ErrDeckEmpty        = errors.New("Deck is empty.")
+	ErrInvalidTarget    = errors.New("Invalid target card.")
+	ErrCannotPass       = errors.New("A card can still be played.")
+)
+
 func (gameState *GameState) IsValidPlayerMove(playerIdx int, move *MoveData) bool {
+	return gameState.ValidatePlayerMove(playerIdx, move) == nil
+}
+
+// Returns the reason the move is not allowed, or nil for a valid move
+func (gameState *GameState) ValidatePlayerMove(playerIdx int, move *MoveData) error {
+	if playerIdx != gameState.ActivePlayer {
+		return ErrNotActivePlayer
+	}
 	switch move.Action {
 	case PlacementAction:
-		hasRequiredData := move.Card != nil && move.Lane != nil
-		return gameState.TurnPhase == PlacementPhase &&
-			hasRequiredData &&
-			gameState.PlayerHands[playerIdx].FindCardIdx(*move.Card) != -1 &&
-			gameState.Lanes.PlayerCanPlaceInLane(playerIdx, *move.Lane)
+		if gameState.TurnPhase != PlacementPhase {
+			return ErrWrongPhase
+		}
+		if move.Card == nil {
+			return ErrMissingMoveData
+		}
+		if gameState.PlayerHands[playerIdx].FindCardIdx(*move.Card) == -1 {
+			return ErrCardNotInHand
+		}
+		if err := gameState.validatePlayCard(playerIdx, *move.Card); err != nil {
+			return err
+		}
+		if move.Card.IsGuile() {
+			return gameState.validateGuileMove(playerIdx, move)
+		}
+		if move.Lane == nil {
+			return ErrMissingMoveData
+		}
+		if move.Card.IsEnvironment() && !gameState.Lanes.PlayerCanModifyLane(*move.Lane, move.Card.Tactic) {
+			return ErrLaneUnavailable
+		}
+		if !move.Card.IsEnvironment() && !gameState.Lanes.PlayerCanPlaceInLane(playerIdx, *move.Lane) {
+			return ErrLaneUnavailable
+		}
+		return nil
 	case ClaimAction:
-		return gameState.TurnPhase == ClaimPhase && move.Lane != nil && gameState.PlayerCanClaimLane(playerIdx, *move.Lane)
+		if gameState.TurnPhase != ClaimPhase {
+			return ErrWrongPhase
+		}
+		if move.Lane == nil {
+			return ErrMissingMoveData
+		}
+		if !gameState.PlayerCanClaimLane(playerIdx, *move.Lane) {
+			return ErrLaneNotClaimable
+		}
+		return nil
+	case EndClaimAction:
+		if gameState.TurnPhase != ClaimPhase {
+			return ErrWrongPhase
+		}
+		return nil
+	case PassAction:
+		if gameState.TurnPhase != PlacementPhase {
+			return ErrWrongPhase
+		}
+		if gameState.PlayerCanPlaceAnyCard(playerIdx) {
+			return ErrCannotPass
+		}
+		return nil
 	case DrawAction:
-		return gameState.TurnPhase == DrawPhase && move.TacticsDeck != nil
+		if gameState.TurnPhase != DrawPhase && gameState.TurnPhase != ScoutDrawPhase {
+			return ErrWrongPhase
+		}
+		if move.TacticsDeck == nil {
+			return ErrMissingMoveData
+		}
+		if (*move.TacticsDeck && len(gameState.TacticsDeck) == 0) || (!*move.TacticsDeck && len(gameState.TroopDeck) == 0) {
+			return ErrDeckEmpty
+		}
+		return nil
+	case ScoutReturnAction:
+		if gameState.TurnPhase != ScoutReturnPhase {
+			return ErrWrongPhase
+		}
+		if move.Card == nil {
+			return ErrMissingMoveData
+		}
+		if gameState.PlayerHands[playerIdx].FindCardIdx(*move.Card) == -1 {
+			return ErrCardNotInHand
+		}
+		return nil
+	default:
+		return ErrUnknownAction
+	}
+}
+
+func (gameState *GameState) IsValidGuileMove(playerIdx int, move *MoveData) bool {
+	return gameState.validateGuileMove(playerIdx, move) == nil
+}
+
+func (gameState *GameState) validateGuileMove(playerIdx int, move *MoveData) error {
+	opponentIdx := 1 - playerIdx
+
+	if move.Card.Tactic == TacticScout {
+		if !gameState.HasDrawableCards() {
+			return ErrDeckEmpty
+		}
+		return nil
+	}
+
+	if move.TargetLane == nil || move.TargetCard == nil {
+		return ErrMissingMoveData
+	}
+	if !gameState.Lanes.IsUnclaimedLane(*move.TargetLane) {
+		return ErrInvalidTarget
+	}
+	targetLane := gameState.Lanes[*move.TargetLane]
+
+	switch move.Card.Tactic {
+	case TacticRedeploy:
+		if !targetLane.CanTakeCard(playerIdx, *move.TargetCard) {
+			return ErrInvalidTarget
+		}
+		// Without a destination lane the card is discarded
+		if move.Lane == nil {
+			return nil
+		}
+		if *move.Lane == *move.TargetLane || !gameState.Lanes.canReceiveCard(playerIdx, *move.Lane, *move.TargetCard) {
+			return ErrLaneUnavailable
+		}
+		return nil
+	case TacticDeserter:
+		if !targetLane.CanTakeCard(opponentIdx, *move.TargetCard) {
+			return ErrInvalidTarget
+		}
+		// The deserting card is always discarded
+		if move.Lane != nil {
+			return ErrLaneUnavailable
+		}
+		return nil
+	case TacticTraitor:
+		if move.TargetCard.IsTactic() || targetLane.Cards[opponentIdx].FindCardIdx(*move.TargetCard) == -1 {
+			return ErrInvalidTarget
+		}
+		if move.Lane == nil {
+			return ErrMissingMoveData
+		}
+		if !gameState.Lanes.PlayerCanPlaceInLane(playerIdx, *move.Lane) {
+			return ErrLaneUnavailable
+		}
+		return nil
 	default:
-		return false
+		return ErrInvalidTarget
+	}
+}
+
+// Whether a card moved by a guile tactic can be put on the player's side of the lane
+func (lanes *GameLanes) canReceiveCard(playerIdx int, laneIdx int, card Card) bool {
+	if card.IsEnvironment() {
+		return lanes.PlayerCanModifyLane(laneIdx, card.Tactic)
+	}
+	return lanes.PlayerCanPlaceInLane(playerIdx, laneIdx)
+}
+
+// Whether the player has any legal card to play in the placement phase
+func (gameState *GameState) PlayerCanPlaceAnyCard(playerIdx int) bool {
+	for _, card := range gameState.PlayerHands[playerIdx] {
+		if !gameState.PlayerCanPlayCard(playerIdx, card) {
+			continue
+		}
+		if card.IsGuile() {
+			if gameState.PlayerCanPlayGuile(playerIdx, card.Tactic) {
+				return true
+			}
+			continue
+		}
+		for laneIdx := range gameState.Lanes {
+			if card.IsEnvironment() && gameState.Lanes.PlayerCanModifyLane(laneIdx, card.Tactic) {
+				return true
+			}
+			if !card.IsEnvironment() && gameState.Lanes.PlayerCanPlaceInLane(playerIdx, laneIdx) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Whether the guile tactic has anything to target
+func (gameState *GameState) PlayerCanPlayGuile(playerIdx int, tactic Tactic) bool {
+	opponentIdx := 1 - playerIdx
+
+	if tactic == TacticScout {
+		return gameState.HasDrawableCards()
 	}
+
+	hasFreeLane := false
+	for laneIdx := range gameState.Lanes {
+		if gameState.Lanes.PlayerCanPlaceInLane(playerIdx, laneIdx) {
+			hasFreeLane = true
+		}
+	}
+
+	for _, lane := range gameState.Lanes {
+		if lane.Claimed != NotClaimed {
+			continue
+		}
+		switch tactic {
+		case TacticRedeploy:
+			if len(lane.TakeableCards(playerIdx)) > 0 {
+				return true
+			}
+		case TacticDeserter:
+			if len(lane.TakeableCards(opponentIdx)) > 0 {
+				return true
+			}
+		case TacticTraitor:
+			if len(lane.Cards[opponentIdx].Troops()) > 0 && hasFreeLane {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (gameState *GameState) PlayerCanPlayCard(playerIdx int, card Card) bool {
+	return gameState.validatePlayCard(playerIdx, card) == nil
+}
+
+func (gameState *GameState) validatePlayCard(playerIdx int, card Card) error {
+	if !card.IsTactic() {
+		return nil
+	}
+	if !gameState.PlayerCanPlayTactic(playerIdx) {
+		return ErrTacticsLimit
+	}
+	if card.IsLeader() && gameState.PlayerHasLeaderOnBoard(playerIdx) {
+		return ErrLeaderOnBoard
+	}
+	return nil
+}
+
+// Applies the active player's move to a copy of the state. The input state is never
+// modified and shares no decks with the returned state.
+func Apply(state *GameState, move *MoveData) (*GameState, []Event, error) {
+	if state.IsOver() {
+		return nil, nil, ErrGameOver
+	}
+	if err := state.ValidatePlayerMove(state.ActivePlayer, move); err != nil {
+		return nil, nil, err
+	}
+
+	newState := state.Clone()
+	newState.ExecutePlayerMove(state.ActivePlayer, move)
+	events := append([]Event{}, newState.Events[len(state.Events):]...)
+
+	return newState, events, nil
 }
 
 func (gameState *GameState) ExecutePlayerMove(playerIdx int, move *MoveData) {
+	event, ok := newMoveEvent(gameState.Turn, playerIdx, move)
+	if !ok {
+		return
+	}
+
+	wasOver := gameState.IsOver()
+	gameState.executePlayerMove(playerIdx, move)
+
+	if move.Action == DrawAction {
+		hand := gameState.PlayerHands[playerIdx]
+		drawnCard := hand[len(hand)-1]
+		event.Card = &drawnCard
+	}
+	gameState.Events = append(gameState.Events, event)
+
+	if gameState.IsOver() && !wasOver {
+		gameState.Events = append(gameState.Events, Event{
+			Type:   EndEvent,
+			Turn:   gameState.Turn,
+			Player: gameState.Winner(),
+		})
+	}
+}
+
+func (gameState *GameState) executePlayerMove(playerIdx int, move *MoveData) {
+	// The game is stuck only if both players pass in a row without anything else changing
+	if move.Action != PassAction && move.Action != EndClaimAction {
+		gameState.ConsecutivePasses = 0
+	}
+
 	switch move.Action {
 	case PlacementAction:
 		cardIdx := gameState.PlayerHands[playerIdx].FindCardIdx(*move.Card)
-
-		gameState.Lanes[*move.Lane].Cards[playerIdx] = append(gameState.Lanes[*move.Lane].Cards[playerIdx], *move.Card)
 		gameState.PlayerHands[playerIdx] = gameState.PlayerHands[playerIdx].RemoveAt(cardIdx)
-		gameState.UpdateClaimableLanes(playerIdx)
-		claimableLanes := []int{}
-		for i, lane := range gameState.Lanes {
-			if lane.Claimable {
-				claimableLanes = append(claimableLanes, i)
-			}
+		if move.Card.IsTactic() {
+			gameState.TacticsPlayed[playerIdx] += 1
+		}
+
+		if move.Card.IsGuile() {
+			gameState.executeGuileMove(playerIdx, move)
+		} else {
+			gameState.Lanes.placeCard(*move.Lane, playerIdx, *move.Card)
 		}
-		if len(claimableLanes) < 1 {
-			gameState.TurnPhase += 1
+
+		if move.Card.Tactic == TacticScout {
+			gameState.TurnPhase = ScoutDrawPhase
+			return
 		}
+		gameState.updateLanes(playerIdx)
 
 	case ClaimAction:
+		lane := &gameState.Lanes[*move.Lane]
 		if playerIdx == 0 {
-			gameState.Lanes[*move.Lane].Claimed = ClaimedByPlayerOne
+			lane.Claimed = ClaimedByPlayerOne
 		} else {
-			gameState.Lanes[*move.Lane].Claimed = ClaimedByPlayerTwo
+			lane.Claimed = ClaimedByPlayerTwo
+		}
+		lane.Claimable = false
+
+		// Any number of lanes can be claimed before the claim phase ends
+		if gameState.HasClaimableLanes() {
+			return
 		}
 
+	case EndClaimAction:
+
+	case PassAction:
+		gameState.ConsecutivePasses += 1
+		gameState.updateLanes(playerIdx)
+		gameState.AdvanceTurnPhase(playerIdx)
+		return
+
 	case DrawAction:
-		newTroopDeck, card := gameState.TroopDeck.Pop()
-		gameState.TroopDeck = newTroopDeck
+		var card Card
+		if *move.TacticsDeck {
+			gameState.TacticsDeck, card = gameState.TacticsDeck.Pop()
+		} else {
+			gameState.TroopDeck, card = gameState.TroopDeck.Pop()
+		}
 		gameState.PlayerHands[playerIdx] = append(gameState.PlayerHands[playerIdx], card)
 
+		if gameState.TurnPhase == ScoutDrawPhase {
+			gameState.ScoutDrawsLeft -= 1
+			if gameState.ScoutDrawsLeft > 0 && gameState.HasDrawableCards() {
+				return
+			}
+			gameState.ScoutDrawsLeft = 0
+		}
+
+	case ScoutReturnAction:
+		cardIdx := gameState.PlayerHands[playerIdx].FindCardIdx(*move.Card)
+		gameState.PlayerHands[playerIdx] = gameState.PlayerHands[playerIdx].RemoveAt(cardIdx)
+		if move.Card.IsTactic() {
+			gameState.TacticsDeck = append(gameState.TacticsDeck, *move.Card)
+		} else {
+			gameState.TroopDeck = append(gameState.TroopDeck, *move.Card)
+		}
+
+		gameState.ScoutReturnsLeft -= 1
+		if gameState.ScoutReturnsLeft > 0 && len(gameState.PlayerHands[playerIdx]) > 0 {
+			return
+		}
+		gameState.ScoutReturnsLeft = 0
+		gameState.updateLanes(playerIdx)
+
 	default:
 		return
 	}
 
-	gameState.TurnPhase += 1
-	if gameState.TurnPhase > DrawPhase {
-		if playerIdx == 0 {
-			gameState.ActivePlayer = 1
+	gameState.AdvanceTurnPhase(playerIdx)
+}
+
+func (gameState *GameState) executeGuileMove(playerIdx int, move *MoveData) {
+	opponentIdx := 1 - playerIdx
+	gameState.DiscardPile = append(gameState.DiscardPile, *move.Card)
+
+	switch move.Card.Tactic {
+	case TacticScout:
+		gameState.ScoutDrawsLeft = ScoutDraws
+		gameState.ScoutReturnsLeft = ScoutReturns
+
+	case TacticRedeploy:
+		card := gameState.Lanes.RemoveAnyCard(*move.TargetLane, playerIdx, *move.TargetCard)
+		if move.Lane == nil {
+			gameState.DiscardPile = append(gameState.DiscardPile, card)
 		} else {
-			gameState.ActivePlayer = 0
+			gameState.Lanes.placeCard(*move.Lane, playerIdx, card)
+		}
+
+	case TacticDeserter:
+		card := gameState.Lanes.RemoveAnyCard(*move.TargetLane, opponentIdx, *move.TargetCard)
+		gameState.DiscardPile = append(gameState.DiscardPile, card)
+
+	case TacticTraitor:
+		card := gameState.Lanes.RemoveCard(*move.TargetLane, opponentIdx, *move.TargetCard)
+		gameState.Lanes.placeCard(*move.Lane, playerIdx, card)
+	}
+}
+
+// Environment cards go on the lane itself and troops and morale tactics on the player's side
+func (lanes *GameLanes) placeCard(laneIdx int, playerIdx int, card Card) {
+	lane := &lanes[laneIdx]
+	if card.IsEnvironment() {
+		lane.Environment[playerIdx] = append(lane.Environment[playerIdx], card)
+		lane.UpdateEnvironment()
+		return
+	}
+	lane.Cards[playerIdx] = append(lane.Cards[playerIdx], card)
+}
+
+func (gameState *GameState) updateLanes(playerIdx int) {
+	for i := range gameState.Lanes {
+		gameState.Lanes[i].UpdateCompletion(playerIdx)
+	}
+	gameState.UpdateClaimableLanes(playerIdx)
+}
+
+// Enumerates every move IsValidPlayerMove accepts for the player in the current turn phase
+func (gameState *GameState) LegalMoves(playerIdx int) []MoveData {
+	moves := []MoveData{}
+
+	switch gameState.TurnPhase {
+	case PlacementPhase:
+		for _, card := range gameState.PlayerHands[playerIdx] {
+			if !gameState.PlayerCanPlayCard(playerIdx, card) {
+				continue
+			}
+			if card.IsGuile() {
+				moves = append(moves, gameState.legalGuileMoves(playerIdx, card)...)
+				continue
+			}
+			for laneIdx := range gameState.Lanes {
+				canPlay := gameState.Lanes.PlayerCanPlaceInLane(playerIdx, laneIdx)
+				if card.IsEnvironment() {
+					canPlay = gameState.Lanes.PlayerCanModifyLane(laneIdx, card.Tactic)
+				}
+				if canPlay {
+					moves = append(moves, MoveData{Action: PlacementAction, Card: &card, Lane: &laneIdx})
+				}
+			}
+		}
+		if len(moves) == 0 {
+			moves = append(moves, MoveData{Action: PassAction})
+		}
+
+	case ClaimPhase:
+		for laneIdx := range gameState.Lanes {
+			if gameState.PlayerCanClaimLane(playerIdx, laneIdx) {
+				moves = append(moves, MoveData{Action: ClaimAction, Lane: &laneIdx})
+			}
+		}
+		moves = append(moves, MoveData{Action: EndClaimAction})
+
+	case DrawPhase, ScoutDrawPhase:
+		if len(gameState.TroopDeck) > 0 {
+			tacticsDeck := false
+			moves = append(moves, MoveData{Action: DrawAction, TacticsDeck: &tacticsDeck})
+		}
+		if len(gameState.TacticsDeck) > 0 {
+			tacticsDeck := true
+			moves = append(moves, MoveData{Action: DrawAction, TacticsDeck: &tacticsDeck})
+		}
+
+	case ScoutReturnPhase:
+		for _, card := range gameState.PlayerHands[playerIdx] {
+			moves = append(moves, MoveData{Action: ScoutReturnAction, Card: &card})
+		}
+	}
+
+	return moves
+}
+
+func (gameState *GameState) legalGuileMoves(playerIdx int, card Card) []MoveData {
+	opponentIdx := 1 - playerIdx
+	moves := []MoveData{}
+
+	if card.Tactic == TacticScout {
+		if gameState.HasDrawableCards() {
+			moves = append(moves, MoveData{Action: PlacementAction, Card: &card})
+		}
+		return moves
+	}
+
+	freeLanes := []int{}
+	for laneIdx := range gameState.Lanes {
+		if gameState.Lanes.PlayerCanPlaceInLane(playerIdx, laneIdx) {
+			freeLanes = append(freeLanes, laneIdx)
+		}
+	}
+
+	for targetLane, lane := range gameState.Lanes {
+		if lane.Claimed != NotClaimed {
+			continue
+		}
+		switch card.Tactic {
+		case TacticRedeploy:
+			for _, target := range lane.TakeableCards(playerIdx) {
+				moves = append(moves, MoveData{Action: PlacementAction, Card: &card, TargetLane: &targetLane, TargetCard: &target})
+				for destination := range gameState.Lanes {
+					if destination != targetLane && gameState.Lanes.canReceiveCard(playerIdx, destination, target) {
+						moves = append(moves, MoveData{Action: PlacementAction, Card: &card, TargetLane: &targetLane, TargetCard: &target, Lane: &destination})
+					}
+				}
+			}
+		case TacticDeserter:
+			for _, target := range lane.TakeableCards(opponentIdx) {
+				moves = append(moves, MoveData{Action: PlacementAction, Card: &card, TargetLane: &targetLane, TargetCard: &target})
+			}
+		case TacticTraitor:
+			for _, target := range lane.Cards[opponentIdx].Troops() {
+				for _, destination := range freeLanes {
+					moves = append(moves, MoveData{Action: PlacementAction, Card: &card, TargetLane: &targetLane, TargetCard: &target, Lane: &destination})
+				}
+			}
 		}
-		gameState.TurnPhase = PlacementPhase
 	}
+	return moves
 }
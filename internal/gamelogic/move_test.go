@@ -0,0 +1,290 @@
+package gamelogic
+
+import (
+	"errors"
+	"testing"
+)
+
+func troop(suit Suit, value int) Card {
+	return Card{Suit: suit, Value: value}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// Fills every lane on the player's side so no troop can be placed
+func fillLanes(gs *GameState, playerIdx int) {
+	for i := range gs.Lanes {
+		for len(gs.Lanes[i].Cards[playerIdx]) < gs.Lanes[i].MaxCards() {
+			gs.Lanes[i].Cards[playerIdx] = append(gs.Lanes[i].Cards[playerIdx], troop(SuitRed, 1))
+		}
+	}
+}
+
+func TestDrawPhaseWithExhaustedDecks(t *testing.T) {
+	tests := []struct {
+		name        string
+		troopDeck   Deck
+		tacticsDeck Deck
+		handSize    int
+		wantPhase   TurnPhase
+		wantActive  int
+	}{
+		{"both decks available", Deck{troop(SuitRed, 5)}, Deck{{Tactic: TacticFog}}, 6, DrawPhase, 0},
+		{"only troops left", Deck{troop(SuitRed, 5)}, Deck{}, 6, DrawPhase, 0},
+		{"only tactics left", Deck{}, Deck{{Tactic: TacticFog}}, 6, DrawPhase, 0},
+		{"both decks empty", Deck{}, Deck{}, 6, PlacementPhase, 1},
+		{"hand full", Deck{troop(SuitRed, 5)}, Deck{}, MaxHandSize, PlacementPhase, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := &GameState{TroopDeck: tt.troopDeck, TacticsDeck: tt.tacticsDeck, TurnPhase: ClaimPhase}
+			for range tt.handSize {
+				gs.PlayerHands[0] = append(gs.PlayerHands[0], troop(SuitBlue, 2))
+			}
+
+			gs.ExecutePlayerMove(0, &MoveData{Action: EndClaimAction})
+
+			if gs.TurnPhase != tt.wantPhase || gs.ActivePlayer != tt.wantActive {
+				t.Errorf("got phase %s for player %d, want phase %s for player %d",
+					gs.TurnPhase, gs.ActivePlayer, tt.wantPhase, tt.wantActive)
+			}
+		})
+	}
+}
+
+func TestDrawFromEmptyDeck(t *testing.T) {
+	tests := []struct {
+		name        string
+		troopDeck   Deck
+		tacticsDeck Deck
+		tactics     bool
+		want        bool
+	}{
+		{"troop deck", Deck{troop(SuitRed, 5)}, Deck{}, false, true},
+		{"empty troop deck", Deck{}, Deck{{Tactic: TacticFog}}, false, false},
+		{"tactics deck", Deck{}, Deck{{Tactic: TacticFog}}, true, true},
+		{"empty tactics deck", Deck{troop(SuitRed, 5)}, Deck{}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := &GameState{TroopDeck: tt.troopDeck, TacticsDeck: tt.tacticsDeck, TurnPhase: DrawPhase}
+			move := &MoveData{Action: DrawAction, TacticsDeck: boolPtr(tt.tactics)}
+
+			if got := gs.IsValidPlayerMove(0, move); got != tt.want {
+				t.Errorf("IsValidPlayerMove() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPass(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(gs *GameState)
+		want  bool
+	}{
+		{
+			name: "troop can be placed",
+			setup: func(gs *GameState) {
+				gs.PlayerHands[0] = Deck{troop(SuitRed, 5)}
+			},
+			want: false,
+		},
+		{
+			name:  "empty hand",
+			setup: func(gs *GameState) {},
+			want:  true,
+		},
+		{
+			name: "all lanes full",
+			setup: func(gs *GameState) {
+				gs.PlayerHands[0] = Deck{troop(SuitRed, 5)}
+				fillLanes(gs, 0)
+			},
+			want: true,
+		},
+		{
+			name: "environment tactic can still be played",
+			setup: func(gs *GameState) {
+				gs.PlayerHands[0] = Deck{troop(SuitRed, 5), {Tactic: TacticMud}}
+				fillLanes(gs, 0)
+			},
+			want: false,
+		},
+		{
+			name: "tactics limit reached",
+			setup: func(gs *GameState) {
+				gs.PlayerHands[0] = Deck{{Tactic: TacticAlexander}}
+				gs.TacticsPlayed = [2]int{1, 0}
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := &GameState{TurnPhase: PlacementPhase}
+			tt.setup(gs)
+
+			if got := gs.IsValidPlayerMove(0, &MoveData{Action: PassAction}); got != tt.want {
+				t.Errorf("IsValidPlayerMove() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGameEndsWhenNeitherPlayerCanMove(t *testing.T) {
+	tests := []struct {
+		name  string
+		moves []MoveAction
+		want  bool
+	}{
+		{"single pass", []MoveAction{PassAction}, false},
+		{"both players pass", []MoveAction{PassAction, PassAction}, true},
+		{"placement between passes", []MoveAction{PassAction, PlacementAction, PassAction}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := &GameState{TurnPhase: PlacementPhase}
+			for _, action := range tt.moves {
+				playerIdx := gs.ActivePlayer
+				move := &MoveData{Action: action}
+				if action == PlacementAction {
+					card := troop(SuitGreen, 3)
+					gs.PlayerHands[playerIdx] = Deck{card}
+					move.Card = &card
+					move.Lane = intPtr(0)
+				}
+				if !gs.IsValidPlayerMove(playerIdx, move) {
+					t.Fatalf("move %s should be valid", action)
+				}
+				gs.ExecutePlayerMove(playerIdx, move)
+			}
+
+			if got := gs.IsOver(); got != tt.want {
+				t.Errorf("IsOver() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyDoesNotModifyState(t *testing.T) {
+	gs := NewGameStateWithSeed(1)
+	gs.TurnPhase = PlacementPhase
+	card := gs.PlayerHands[gs.ActivePlayer][0]
+	before := gs.Clone()
+
+	newState, events, err := Apply(gs, &MoveData{Action: PlacementAction, Card: &card, Lane: intPtr(4)})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(events) == 0 || events[0].Type != PlaceEvent {
+		t.Errorf("Apply() events = %v, want a place event", events)
+	}
+
+	newState.Lanes[4].Cards[before.ActivePlayer][0] = troop(SuitOrange, 10)
+	newState.PlayerHands[before.ActivePlayer][0] = troop(SuitOrange, 10)
+	if len(gs.Lanes[4].Cards[before.ActivePlayer]) != 0 || gs.PlayerHands[before.ActivePlayer][0] != card {
+		t.Errorf("Apply() modified the input state")
+	}
+	if len(gs.Events) != len(before.Events) {
+		t.Errorf("Apply() appended to the input event log")
+	}
+
+	events[0].Type = EndEvent
+	if newState.Events[len(newState.Events)-len(events)].Type != PlaceEvent {
+		t.Errorf("Apply() events share memory with the new state's event log")
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		phase   TurnPhase
+		move    MoveData
+		wantErr error
+	}{
+		{"wrong phase", ClaimPhase, MoveData{Action: PassAction}, ErrWrongPhase},
+		{"unknown action", PlacementPhase, MoveData{Action: "resign"}, ErrUnknownAction},
+		{"missing card", PlacementPhase, MoveData{Action: PlacementAction, Lane: intPtr(0)}, ErrMissingMoveData},
+		{"card not in hand", PlacementPhase, MoveData{Action: PlacementAction, Card: &Card{Tactic: TacticFog}, Lane: intPtr(0)}, ErrCardNotInHand},
+		{"cannot pass", PlacementPhase, MoveData{Action: PassAction}, ErrCannotPass},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gs := NewGameStateWithSeed(1)
+			gs.TurnPhase = tt.phase
+
+			if _, _, err := Apply(gs, &tt.move); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Apply() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePlayerMoveNotActivePlayer(t *testing.T) {
+	gs := NewGameStateWithSeed(1)
+	opponentIdx := 1 - gs.ActivePlayer
+	card := gs.PlayerHands[opponentIdx][0]
+
+	move := MoveData{Action: PlacementAction, Card: &card, Lane: intPtr(0)}
+	if err := gs.ValidatePlayerMove(opponentIdx, &move); !errors.Is(err, ErrNotActivePlayer) {
+		t.Errorf("ValidatePlayerMove() error = %v, want %v", err, ErrNotActivePlayer)
+	}
+}
+
+func TestGuileTacticsOnEnvironmentCards(t *testing.T) {
+	fog, mud := Card{Tactic: TacticFog}, Card{Tactic: TacticMud}
+
+	t.Run("deserter discards opponent's fog", func(t *testing.T) {
+		gs := &GameState{TurnPhase: PlacementPhase}
+		gs.PlayerHands[0] = Deck{{Tactic: TacticDeserter}}
+		gs.Lanes.placeCard(2, 1, fog)
+
+		move := MoveData{Action: PlacementAction, Card: &Card{Tactic: TacticDeserter}, TargetLane: intPtr(2), TargetCard: &fog}
+		if err := gs.ValidatePlayerMove(0, &move); err != nil {
+			t.Fatalf("ValidatePlayerMove() error = %v", err)
+		}
+		gs.ExecutePlayerMove(0, &move)
+		if gs.Lanes[2].Fog || len(gs.Lanes[2].Environment[1]) != 0 || gs.DiscardPile.FindCardIdx(fog) == -1 {
+			t.Errorf("fog was not discarded: lane %+v, discard pile %v", gs.Lanes[2], gs.DiscardPile)
+		}
+	})
+
+	t.Run("redeploy moves own mud", func(t *testing.T) {
+		gs := &GameState{TurnPhase: PlacementPhase}
+		gs.PlayerHands[0] = Deck{{Tactic: TacticRedeploy}}
+		gs.Lanes.placeCard(2, 0, mud)
+
+		move := MoveData{Action: PlacementAction, Card: &Card{Tactic: TacticRedeploy}, TargetLane: intPtr(2), TargetCard: &mud, Lane: intPtr(5)}
+		if err := gs.ValidatePlayerMove(0, &move); err != nil {
+			t.Fatalf("ValidatePlayerMove() error = %v", err)
+		}
+		gs.ExecutePlayerMove(0, &move)
+		if gs.Lanes[2].Mud || !gs.Lanes[5].Mud || gs.Lanes[5].Environment[0].FindCardIdx(mud) == -1 {
+			t.Errorf("mud was not moved: lane 2 %+v, lane 5 %+v", gs.Lanes[2], gs.Lanes[5])
+		}
+	})
+
+	t.Run("mud stays while a side has four cards", func(t *testing.T) {
+		gs := &GameState{TurnPhase: PlacementPhase}
+		gs.PlayerHands[0] = Deck{{Tactic: TacticDeserter}}
+		gs.Lanes.placeCard(2, 1, mud)
+		gs.Lanes[2].Cards[0] = Deck{troop(SuitRed, 1), troop(SuitRed, 2), troop(SuitRed, 3), troop(SuitRed, 4)}
+
+		move := MoveData{Action: PlacementAction, Card: &Card{Tactic: TacticDeserter}, TargetLane: intPtr(2), TargetCard: &mud}
+		if err := gs.ValidatePlayerMove(0, &move); !errors.Is(err, ErrInvalidTarget) {
+			t.Errorf("ValidatePlayerMove() error = %v, want %v", err, ErrInvalidTarget)
+		}
+	})
+}
@@ -0,0 +1,376 @@
+package gamelogic
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Move notation, with lanes numbered 1-9:
+//
+//	R5>3      place red 5 in lane 3 (also tactics cards, e.g. AL>3 or FO>3)
+//	SC        play Scout
+//	RE:R5@2>7 Redeploy own red 5 from lane 2 to lane 7, RE:R5@2 discards it
+//	DE:G7@4   Deserter discards the opponent's green 7 from lane 4
+//	TR:G7@4>2 Traitor takes the opponent's green 7 from lane 4 to lane 2
+//	C3        claim lane 3
+//	E         end the claim phase
+//	D / DT    draw from the troop or the tactics deck
+//	^R5       return red 5 to the top of its deck after a Scout
+//	P         pass
+
+var tacticNotation = map[Tactic]string{
+	TacticAlexander:        "AL",
+	TacticDarius:           "DA",
+	TacticCompanionCavalry: "CC",
+	TacticShieldBearers:    "SB",
+	TacticFog:              "FO",
+	TacticMud:              "MU",
+	TacticScout:            "SC",
+	TacticRedeploy:         "RE",
+	TacticDeserter:         "DE",
+	TacticTraitor:          "TR",
+}
+
+var ErrInvalidNotation = errors.New("Invalid notation.")
+
+func (c Card) Notation() string {
+	if c.IsTactic() {
+		return tacticNotation[c.Tactic]
+	}
+	return strings.ReplaceAll(c.String(), " ", "")
+}
+
+func ParseCard(s string) (Card, error) {
+	for tactic, notation := range tacticNotation {
+		if s == notation {
+			return Card{Tactic: tactic}, nil
+		}
+	}
+
+	if len(s) < 2 {
+		return Card{}, fmt.Errorf("%w Unknown card %q", ErrInvalidNotation, s)
+	}
+	for _, suit := range Suits {
+		if s[:1] != strings.ToUpper(suit.String())[:1] {
+			continue
+		}
+		value, err := strconv.Atoi(s[1:])
+		if err != nil || value < 1 || value > 10 {
+			break
+		}
+		// Only the notation Notation writes is accepted, so "R05" or "R+5" are rejected
+		card := Card{Suit: suit, Value: value}
+		if card.Notation() != s {
+			break
+		}
+		return card, nil
+	}
+	return Card{}, fmt.Errorf("%w Unknown card %q", ErrInvalidNotation, s)
+}
+
+func formatLane(lane *int) string {
+	if lane == nil {
+		return "?"
+	}
+	return strconv.Itoa(*lane + 1)
+}
+
+func parseLane(s string) (*int, error) {
+	lane, err := strconv.Atoi(s)
+	if err != nil || lane < 1 || lane > len(GameLanes{}) {
+		return nil, fmt.Errorf("%w Unknown lane %q", ErrInvalidNotation, s)
+	}
+	lane -= 1
+	return &lane, nil
+}
+
+func (m MoveData) String() string {
+	card := "?"
+	if m.Card != nil {
+		card = m.Card.Notation()
+	}
+
+	switch m.Action {
+	case PlacementAction:
+		if m.Card == nil || !m.Card.IsGuile() {
+			return card + ">" + formatLane(m.Lane)
+		}
+		if m.Card.Tactic == TacticScout {
+			return card
+		}
+		target := "?"
+		if m.TargetCard != nil {
+			target = m.TargetCard.Notation()
+		}
+		s := card + ":" + target + "@" + formatLane(m.TargetLane)
+		if m.Lane != nil {
+			s += ">" + formatLane(m.Lane)
+		}
+		return s
+	case ClaimAction:
+		return "C" + formatLane(m.Lane)
+	case EndClaimAction:
+		return "E"
+	case PassAction:
+		return "P"
+	case DrawAction:
+		if m.TacticsDeck != nil && *m.TacticsDeck {
+			return "DT"
+		}
+		return "D"
+	case ScoutReturnAction:
+		return "^" + card
+	default:
+		return string(m.Action)
+	}
+}
+
+func ParseMove(s string) (*MoveData, error) {
+	switch {
+	case s == "E":
+		return &MoveData{Action: EndClaimAction}, nil
+	case s == "P":
+		return &MoveData{Action: PassAction}, nil
+	case s == "D" || s == "DT":
+		tacticsDeck := s == "DT"
+		return &MoveData{Action: DrawAction, TacticsDeck: &tacticsDeck}, nil
+	case strings.HasPrefix(s, "^"):
+		card, err := ParseCard(s[1:])
+		if err != nil {
+			return nil, err
+		}
+		return &MoveData{Action: ScoutReturnAction, Card: &card}, nil
+	case len(s) == 2 && s[0] == 'C' && s[1] >= '1' && s[1] <= '9':
+		lane, err := parseLane(s[1:])
+		if err != nil {
+			return nil, err
+		}
+		return &MoveData{Action: ClaimAction, Lane: lane}, nil
+	}
+
+	cardPart, target, isGuile := strings.Cut(s, ":")
+	destination := ""
+	if isGuile {
+		target, destination, _ = strings.Cut(target, ">")
+	} else {
+		cardPart, destination, _ = strings.Cut(cardPart, ">")
+	}
+
+	card, err := ParseCard(cardPart)
+	if err != nil {
+		return nil, err
+	}
+	move := &MoveData{Action: PlacementAction, Card: &card}
+
+	if destination != "" {
+		if move.Lane, err = parseLane(destination); err != nil {
+			return nil, err
+		}
+	}
+
+	if card.Tactic == TacticScout {
+		if isGuile || destination != "" {
+			return nil, fmt.Errorf("%w Scout takes no target %q", ErrInvalidNotation, s)
+		}
+		return move, nil
+	}
+
+	if !isGuile {
+		if card.IsGuile() || move.Lane == nil {
+			return nil, fmt.Errorf("%w Missing lane or target %q", ErrInvalidNotation, s)
+		}
+		return move, nil
+	}
+
+	targetCard, targetLane, found := strings.Cut(target, "@")
+	if !card.IsGuile() || !found {
+		return nil, fmt.Errorf("%w Invalid target %q", ErrInvalidNotation, s)
+	}
+	parsedTarget, err := ParseCard(targetCard)
+	if err != nil {
+		return nil, err
+	}
+	move.TargetCard = &parsedTarget
+	if move.TargetLane, err = parseLane(targetLane); err != nil {
+		return nil, err
+	}
+	return move, nil
+}
+
+// Game record headers, similar to chess PGN tags
+const (
+	HeaderEvent   = "Event"
+	HeaderDate    = "Date"
+	HeaderPlayer1 = "Player1"
+	HeaderPlayer2 = "Player2"
+	HeaderSeed    = "Seed"
+	HeaderResult  = "Result"
+	// Number of hints each player asked for, in games with hints enabled
+	HeaderHints1 = "Hints1"
+	HeaderHints2 = "Hints2"
+)
+
+var headerOrder = []string{HeaderEvent, HeaderDate, HeaderPlayer1, HeaderPlayer2, HeaderSeed, HeaderResult, HeaderHints1, HeaderHints2}
+
+const (
+	ResultPlayerOneWins = "1-0"
+	ResultPlayerTwoWins = "0-1"
+	ResultDraw          = "1/2-1/2"
+	ResultOngoing       = "*"
+)
+
+type RecordedMove struct {
+	Turn int
+	Move MoveData
+}
+
+type GameRecord struct {
+	Headers map[string]string
+	Moves   []RecordedMove
+}
+
+func GameResult(gs *GameState) string {
+	switch {
+	case gs.Winner() == 0:
+		return ResultPlayerOneWins
+	case gs.Winner() == 1:
+		return ResultPlayerTwoWins
+	case gs.IsOver():
+		return ResultDraw
+	default:
+		return ResultOngoing
+	}
+}
+
+// Builds a record of the game from its event log. The given headers are added to
+// the seed and result headers taken from the game and the date the game was played,
+// which is left out when it is zero.
+func NewGameRecord(gs *GameState, playedAt time.Time, headers map[string]string) *GameRecord {
+	record := &GameRecord{
+		Headers: map[string]string{
+			HeaderEvent:  "Battleline",
+			HeaderSeed:   strconv.FormatUint(gs.Seed, 10),
+			HeaderResult: GameResult(gs),
+		},
+		Moves: []RecordedMove{},
+	}
+	if !playedAt.IsZero() {
+		record.Headers[HeaderDate] = playedAt.UTC().Format("2006.01.02")
+	}
+	for key, value := range headers {
+		record.Headers[key] = value
+	}
+
+	for _, event := range gs.Events {
+		if event.Move != nil {
+			record.Moves = append(record.Moves, RecordedMove{Turn: event.Turn, Move: *event.Move})
+		}
+	}
+	return record
+}
+
+func (r *GameRecord) String() string {
+	var sb strings.Builder
+
+	keys := []string{}
+	for _, key := range headerOrder {
+		if _, ok := r.Headers[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	extraKeys := []string{}
+	for key := range r.Headers {
+		if !slices.Contains(headerOrder, key) {
+			extraKeys = append(extraKeys, key)
+		}
+	}
+	sort.Strings(extraKeys)
+
+	for _, key := range append(keys, extraKeys...) {
+		fmt.Fprintf(&sb, "[%s %q]\n", key, r.Headers[key])
+	}
+	sb.WriteString("\n")
+
+	turn := -1
+	tokens := []string{}
+	for _, recorded := range r.Moves {
+		if recorded.Turn != turn {
+			turn = recorded.Turn
+			tokens = append(tokens, fmt.Sprintf("%d.", turn+1))
+		}
+		tokens = append(tokens, recorded.Move.String())
+	}
+	if result, ok := r.Headers[HeaderResult]; ok {
+		tokens = append(tokens, result)
+	}
+
+	sb.WriteString(strings.Join(tokens, " "))
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+func ParseGameRecord(s string) (*GameRecord, error) {
+	record := &GameRecord{Headers: map[string]string{}, Moves: []RecordedMove{}}
+	turn := 0
+
+	for lineNumber, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			key, value, found := strings.Cut(strings.Trim(line, "[]"), " ")
+			unquoted, err := strconv.Unquote(value)
+			if !found || err != nil {
+				return nil, fmt.Errorf("line %d: %w Invalid header %q", lineNumber+1, ErrInvalidNotation, line)
+			}
+			record.Headers[key] = unquoted
+			continue
+		}
+
+		for _, token := range strings.Fields(line) {
+			switch token {
+			case ResultPlayerOneWins, ResultPlayerTwoWins, ResultDraw, ResultOngoing:
+				continue
+			}
+			if number, isTurn := strings.CutSuffix(token, "."); isTurn {
+				n, err := strconv.Atoi(number)
+				if err != nil || n < 1 {
+					return nil, fmt.Errorf("line %d: %w Invalid turn %q", lineNumber+1, ErrInvalidNotation, token)
+				}
+				turn = n - 1
+				continue
+			}
+
+			move, err := ParseMove(token)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber+1, err)
+			}
+			record.Moves = append(record.Moves, RecordedMove{Turn: turn, Move: *move})
+		}
+	}
+
+	return record, nil
+}
+
+// Replays the recorded moves from the seed header
+func (r *GameRecord) Replay() (*GameState, error) {
+	seed, err := strconv.ParseUint(r.Headers[HeaderSeed], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w Invalid seed %q", ErrInvalidNotation, r.Headers[HeaderSeed])
+	}
+
+	gs := NewGameStateWithSeed(seed)
+	for i, recorded := range r.Moves {
+		if gs, _, err = Apply(gs, &recorded.Move); err != nil {
+			return nil, fmt.Errorf("move %d (%s): %w", i+1, recorded.Move, err)
+		}
+	}
+	return gs, nil
+}
@@ -0,0 +1,84 @@
+package gamelogic
+
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMoveNotationRoundTrip(t *testing.T) {
+	tests := []struct {
+		notation string
+		move     MoveData
+	}{
+		{"R5>3", MoveData{Action: PlacementAction, Card: &Card{Suit: SuitRed, Value: 5}, Lane: intPtr(2)}},
+		{"O10>9", MoveData{Action: PlacementAction, Card: &Card{Suit: SuitOrange, Value: 10}, Lane: intPtr(8)}},
+		{"CC>1", MoveData{Action: PlacementAction, Card: &Card{Tactic: TacticCompanionCavalry}, Lane: intPtr(0)}},
+		{"SC", MoveData{Action: PlacementAction, Card: &Card{Tactic: TacticScout}}},
+		{"RE:R5@2", MoveData{Action: PlacementAction, Card: &Card{Tactic: TacticRedeploy}, TargetCard: &Card{Suit: SuitRed, Value: 5}, TargetLane: intPtr(1)}},
+		{"TR:G7@4>2", MoveData{Action: PlacementAction, Card: &Card{Tactic: TacticTraitor}, TargetCard: &Card{Suit: SuitGreen, Value: 7}, TargetLane: intPtr(3), Lane: intPtr(1)}},
+		{"C3", MoveData{Action: ClaimAction, Lane: intPtr(2)}},
+		{"E", MoveData{Action: EndClaimAction}},
+		{"P", MoveData{Action: PassAction}},
+		{"D", MoveData{Action: DrawAction, TacticsDeck: boolPtr(false)}},
+		{"DT", MoveData{Action: DrawAction, TacticsDeck: boolPtr(true)}},
+		{"^AL", MoveData{Action: ScoutReturnAction, Card: &Card{Tactic: TacticAlexander}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.notation, func(t *testing.T) {
+			if got := tt.move.String(); got != tt.notation {
+				t.Errorf("String() = %q, want %q", got, tt.notation)
+			}
+			move, err := ParseMove(tt.notation)
+			if err != nil {
+				t.Fatalf("ParseMove() error = %v", err)
+			}
+			if move.String() != tt.notation {
+				t.Errorf("ParseMove(%q) = %q", tt.notation, move)
+			}
+		})
+	}
+}
+
+func TestParseMoveErrors(t *testing.T) {
+	for _, notation := range []string{"", "X5>3", "R11>3", "R5>0", "R5", "DE:R5", "SC>3", "R5:G7@4", "C0", "R05>3", "R+5>3", "DE:B05@2"} {
+		if _, err := ParseMove(notation); err == nil {
+			t.Errorf("ParseMove(%q) should fail", notation)
+		}
+	}
+}
+
+func TestGameRecordRoundTrip(t *testing.T) {
+	gs := NewGameStateWithSeed(42)
+	rng := rand.New(rand.NewPCG(42, 42))
+	for !gs.IsOver() {
+		moves := gs.LegalMoves(gs.ActivePlayer)
+		gs.ExecutePlayerMove(gs.ActivePlayer, &moves[rng.IntN(len(moves))])
+	}
+
+	playedAt := time.Date(2024, 3, 9, 23, 30, 0, 0, time.UTC)
+	record := NewGameRecord(gs, playedAt, map[string]string{HeaderPlayer1: "Alice", HeaderPlayer2: "Bob", HeaderHints1: "2", HeaderHints2: "0"})
+	if record.Headers[HeaderDate] != "2024.03.09" {
+		t.Errorf("Date header = %q, want %q", record.Headers[HeaderDate], "2024.03.09")
+	}
+	if !strings.Contains(record.String(), "[Result \""+GameResult(gs)+"\"]\n[Hints1 \"2\"]\n[Hints2 \"0\"]\n") {
+		t.Errorf("hint headers are missing or out of order:\n%s", record)
+	}
+	parsed, err := ParseGameRecord(record.String())
+	if err != nil {
+		t.Fatalf("ParseGameRecord() error = %v", err)
+	}
+	if parsed.String() != record.String() {
+		t.Errorf("record does not round trip:\n%s\n%s", record, parsed)
+	}
+
+	replayed, err := parsed.Replay()
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if GameResult(replayed) != parsed.Headers[HeaderResult] {
+		t.Errorf("replayed result = %s, want %s", GameResult(replayed), parsed.Headers[HeaderResult])
+	}
+}
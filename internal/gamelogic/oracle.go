@@ -0,0 +1,135 @@
+package gamelogic
+
+// Sum of the highest count values in the set, or -1 if the set has too few cards
+func (s CardSet) topSum(count int) int {
+	sum := 0
+	for v := 10; v >= 1 && count > 0; v-- {
+		n := min(count, s.OfValue(v).Count())
+		sum += n * v
+		count -= n
+	}
+	if count > 0 {
+		return -1
+	}
+	return sum
+}
+
+// Returns the best lane value the side can still reach by adding cards from available.
+// The value is encoded like GetTotalValue. Unplayed tactics cards are never considered.
+// A side that can no longer be completed is worth 0.
+func (deck Deck) BestPossibleValue(available CardSet, maxCards int, fog bool) int {
+	if len(deck) >= maxCards {
+		return deck.GetLaneValue(fog)
+	}
+	if !deck.HasWildcards() {
+		return bestCompletion(deck, available, maxCards, fog)
+	}
+
+	best := 0
+	deck.ForEachResolution(func(resolved Deck) {
+		best = max(best, bestCompletion(resolved, available, maxCards, fog))
+	})
+	return best
+}
+
+// Checks each formation from strongest to weakest, so the first completion found is the best one
+func bestCompletion(side Deck, available CardSet, maxCards int, fog bool) int {
+	missing := maxCards - len(side)
+	if available.Count() < missing {
+		return 0
+	}
+
+	sideSum, sameSuit, sameValue := 0, true, true
+	for _, c := range side {
+		sideSum += c.Value
+		sameSuit = sameSuit && c.Suit == side[0].Suit
+		sameValue = sameValue && c.Value == side[0].Value
+	}
+
+	if fog {
+		return sideSum + available.topSum(missing)
+	}
+
+	if sameSuit {
+		if value := bestStraight(side, available, maxCards, true); value > 0 {
+			return int(FormationWedge)*100 + value
+		}
+	}
+
+	if sameValue {
+		for v := 10; v >= 1; v-- {
+			if len(side) > 0 && side[0].Value != v {
+				continue
+			}
+			if available.OfValue(v).Count() >= missing {
+				return int(FormationSquare)*100 + maxCards*v
+			}
+		}
+	}
+
+	if sameSuit {
+		best := -1
+		for _, suit := range Suits {
+			if len(side) > 0 && side[0].Suit != suit {
+				continue
+			}
+			best = max(best, available.OfSuit(suit).topSum(missing))
+		}
+		if best >= 0 {
+			return int(FormationColumn)*100 + sideSum + best
+		}
+	}
+
+	if value := bestStraight(side, available, maxCards, false); value > 0 {
+		return int(FormationSkirmish)*100 + value
+	}
+
+	return int(FormationFray)*100 + sideSum + available.topSum(missing)
+}
+
+// Returns the sum of the highest straight the side can complete, or 0 if there is none.
+// A suited straight must use the suit of the side, or any single suit for an empty side.
+func bestStraight(side Deck, available CardSet, maxCards int, suited bool) int {
+	suits := Suits
+	if suited && len(side) > 0 {
+		suits = []Suit{side[0].Suit}
+	}
+
+	for start := 10 - maxCards + 1; start >= 1; start-- {
+		end := start + maxCards - 1
+
+		var used [11]bool
+		fits := true
+		for _, c := range side {
+			if c.Value < start || c.Value > end || used[c.Value] {
+				fits = false
+				break
+			}
+			used[c.Value] = true
+		}
+		if !fits {
+			continue
+		}
+
+		for _, suit := range suits {
+			complete := true
+			for v := start; v <= end && complete; v++ {
+				if used[v] {
+					continue
+				}
+				if suited {
+					complete = available.Has(Card{Suit: suit, Value: v})
+				} else {
+					complete = !available.OfValue(v).IsEmpty()
+				}
+			}
+			if complete {
+				return (start + end) * maxCards / 2
+			}
+			if !suited {
+				break
+			}
+		}
+	}
+	return 0
+}
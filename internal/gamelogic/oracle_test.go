@@ -0,0 +1,150 @@
+package gamelogic
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+// Exhaustive search over every set of cards that could complete the side
+func bruteForceBestPossibleValue(side Deck, possibleCards Deck, maxCards int, fog bool) int {
+	possibleCards = possibleCards.Troops()
+	missing := maxCards - len(side)
+	if len(possibleCards) < missing {
+		return 0
+	}
+
+	best := 0
+	var search func(start int, current Deck)
+	search = func(start int, current Deck) {
+		if len(current) == maxCards {
+			best = max(best, current.GetLaneValue(fog))
+			return
+		}
+		for i := start; i < len(possibleCards); i++ {
+			search(i+1, append(current.Copy(), possibleCards[i]))
+		}
+	}
+	search(0, side.Copy())
+	return best
+}
+
+// Deals a random side and up to maxPossible of the remaining troop cards as the possible cards
+func randomPosition(rng *rand.Rand, sideSize int, maxPossible int) (side Deck, possibleCards Deck) {
+	deck := CreateTroopDeck().Shuffle(rng)
+	possibleSize := min(len(deck)-sideSize, 1+rng.IntN(maxPossible))
+	return deck[:sideSize], deck[sideSize : sideSize+possibleSize]
+}
+
+func TestBestPossibleValue(t *testing.T) {
+	tests := []struct {
+		name          string
+		side          Deck
+		possibleCards Deck
+		maxCards      int
+		fog           bool
+		want          int
+	}{
+		{
+			name:          "wedge",
+			side:          Deck{troop(SuitRed, 8)},
+			possibleCards: Deck{troop(SuitRed, 9), troop(SuitRed, 10), troop(SuitBlue, 10)},
+			maxCards:      MaxCardsPerSide,
+			want:          527,
+		},
+		{
+			name:          "square beats column",
+			side:          Deck{troop(SuitRed, 2), troop(SuitBlue, 2)},
+			possibleCards: Deck{troop(SuitGreen, 2), troop(SuitRed, 10)},
+			maxCards:      MaxCardsPerSide,
+			want:          406,
+		},
+		{
+			name:          "fog ignores formations",
+			side:          Deck{troop(SuitRed, 8)},
+			possibleCards: Deck{troop(SuitRed, 9), troop(SuitRed, 10), troop(SuitBlue, 10)},
+			maxCards:      MaxCardsPerSide,
+			fog:           true,
+			want:          28,
+		},
+		{
+			name:          "mud needs four cards",
+			side:          Deck{troop(SuitRed, 8)},
+			possibleCards: Deck{troop(SuitRed, 9), troop(SuitRed, 10), troop(SuitRed, 7)},
+			maxCards:      MudMaxCardsPerSide,
+			want:          534,
+		},
+		{
+			name:          "leader completes wedge",
+			side:          Deck{troop(SuitRed, 8), {Tactic: TacticAlexander}},
+			possibleCards: Deck{troop(SuitRed, 10), troop(SuitBlue, 1)},
+			maxCards:      MaxCardsPerSide,
+			want:          527,
+		},
+		{
+			name:          "side cannot be completed",
+			side:          Deck{troop(SuitRed, 8)},
+			possibleCards: Deck{troop(SuitRed, 10)},
+			maxCards:      MaxCardsPerSide,
+			want:          0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.side.BestPossibleValue(NewCardSet(tt.possibleCards), tt.maxCards, tt.fog); got != tt.want {
+				t.Errorf("BestPossibleValue() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBestPossibleValueMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	for i := range 500 {
+		maxCards := MaxCardsPerSide
+		if i%3 == 0 {
+			maxCards = MudMaxCardsPerSide
+		}
+		side, possibleCards := randomPosition(rng, rng.IntN(maxCards), 30)
+		if i%5 == 0 {
+			side = append(side, Card{Tactic: TacticCompanionCavalry})
+		}
+		fog := i%7 == 0
+
+		want := bruteForceBestPossibleValue(side, possibleCards, maxCards, fog)
+		if got := side.BestPossibleValue(NewCardSet(possibleCards), maxCards, fog); got != want {
+			t.Fatalf("BestPossibleValue(%s) = %d, brute force = %d (possible cards %s)", side, got, want, possibleCards)
+		}
+	}
+}
+
+func BenchmarkBestPossibleValue(b *testing.B) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	side, possibleCards := randomPosition(rng, 1, 50)
+
+	for b.Loop() {
+		side.BestPossibleValue(NewCardSet(possibleCards), MaxCardsPerSide, false)
+	}
+}
+
+func BenchmarkBestPossibleValueBruteForce(b *testing.B) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	side, possibleCards := randomPosition(rng, 1, 50)
+
+	for b.Loop() {
+		bruteForceBestPossibleValue(side, possibleCards, MaxCardsPerSide, false)
+	}
+}
+
+func BenchmarkUpdateClaimableLanes(b *testing.B) {
+	gs := NewGameStateWithSeed(1)
+	for i := range gs.Lanes {
+		gs.Lanes[i].Cards[0], gs.TroopDeck = gs.TroopDeck[:3], gs.TroopDeck[3:]
+		gs.Lanes[i].Cards[1], gs.TroopDeck = gs.TroopDeck[:1], gs.TroopDeck[1:]
+	}
+
+	for b.Loop() {
+		gs.UpdateClaimableLanes(0)
+	}
+}
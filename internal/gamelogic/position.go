@@ -0,0 +1,303 @@
+package gamelogic
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A position is a single line of space separated fields, similar to chess FEN:
+//
+//	lanes hand1 hand2 troopDeck tacticsDeck discardPile activePlayer turnPhase tacticsPlayed scout passes turn
+//
+// Lanes are separated by "/" and each lane is written as "side1|side2|status". The status
+// holds who claimed the lane and who completed their side first (1, 2 or -) followed by
+// "f" for Fog and "m" for Mud, each with the player who played it. Decks list cards in
+// move notation from the bottom to the top, or "-" when empty. The tactics played and the
+// scout draws and returns left are written as two comma separated numbers and the turn is
+// numbered from 1. In this position from turn 30 the ninth lane was claimed by player 1
+// after player 2 completed their side first, and player 2 played Fog in the third lane:
+//
+//	SB,B6|-|--/CC|G7|--/R5,B3|O4,P7|--f2/G3,R3|-|--/-|G4|--/P9|-|--/P6,B1|R4,B9|--/AL|R2,Y4,Y8|-2/G8,O7,Y7|Y3,O2,G2|12 Y1,P1,O3,B8,G5,B5,P5 O5,DE,DA,MU,B4,SC,O9 R6,Y9,O10,R9,B2,Y6,O6,R10,R1,P8,O1,B10,Y2,G9,P10,P2,B7,Y5,R7,P3,G1,Y10,O8,P4,G10,G6,R8 - RE,TR 2 placement 3,3 0,0 0 30
+
+const positionFields = 12
+
+var ErrInvalidPosition = errors.New("Invalid position.")
+
+func formatDeck(deck Deck) string {
+	if len(deck) == 0 {
+		return "-"
+	}
+	cards := make([]string, len(deck))
+	for i, c := range deck {
+		cards[i] = c.Notation()
+	}
+	return strings.Join(cards, ",")
+}
+
+func parseDeck(s string) (Deck, error) {
+	deck := Deck{}
+	if s == "-" {
+		return deck, nil
+	}
+	for _, token := range strings.Split(s, ",") {
+		c, err := ParseCard(token)
+		if err != nil {
+			return nil, err
+		}
+		deck = append(deck, c)
+	}
+	return deck, nil
+}
+
+func formatPlayerMark(mark int) string {
+	if mark == 0 {
+		return "-"
+	}
+	return strconv.Itoa(mark)
+}
+
+func parsePlayerMark(b byte) (int, bool) {
+	switch b {
+	case '-':
+		return 0, true
+	case '1', '2':
+		return int(b - '0'), true
+	}
+	return 0, false
+}
+
+var environmentFlags = map[Tactic]rune{TacticFog: 'f', TacticMud: 'm'}
+
+func formatLaneStatus(lane *Lane) string {
+	s := formatPlayerMark(lane.Claimed) + formatPlayerMark(lane.CompletedFirst)
+	for _, tactic := range []Tactic{TacticFog, TacticMud} {
+		for playerIdx, side := range lane.Environment {
+			if side.FindCardIdx(Card{Tactic: tactic}) != -1 {
+				s += string(environmentFlags[tactic]) + formatPlayerMark(playerIdx+1)
+			}
+		}
+	}
+	return s
+}
+
+func formatPair(pair [2]int) string {
+	return strconv.Itoa(pair[0]) + "," + strconv.Itoa(pair[1])
+}
+
+func parsePair(s string) ([2]int, error) {
+	first, second, found := strings.Cut(s, ",")
+	a, errA := strconv.Atoi(first)
+	b, errB := strconv.Atoi(second)
+	if !found || errA != nil || errB != nil || a < 0 || b < 0 {
+		return [2]int{}, fmt.Errorf("%w Invalid pair %q", ErrInvalidPosition, s)
+	}
+	return [2]int{a, b}, nil
+}
+
+// Encodes everything needed to continue the game. The seed and the event log are not included.
+func (gs *GameState) Position() string {
+	lanes := make([]string, len(gs.Lanes))
+	for i := range gs.Lanes {
+		lane := &gs.Lanes[i]
+		lanes[i] = strings.Join([]string{
+			formatDeck(lane.Cards[0]),
+			formatDeck(lane.Cards[1]),
+			formatLaneStatus(lane),
+		}, "|")
+	}
+
+	return strings.Join([]string{
+		strings.Join(lanes, "/"),
+		formatDeck(gs.PlayerHands[0]),
+		formatDeck(gs.PlayerHands[1]),
+		formatDeck(gs.TroopDeck),
+		formatDeck(gs.TacticsDeck),
+		formatDeck(gs.DiscardPile),
+		strconv.Itoa(gs.ActivePlayer + 1),
+		gs.TurnPhase.String(),
+		formatPair(gs.TacticsPlayed),
+		formatPair([2]int{gs.ScoutDrawsLeft, gs.ScoutReturnsLeft}),
+		strconv.Itoa(gs.ConsecutivePasses),
+		strconv.Itoa(gs.Turn + 1),
+	}, " ")
+}
+
+func parseLaneField(s string) (Lane, error) {
+	lane := Lane{}
+	parts := strings.Split(s, "|")
+	if len(parts) != 3 || len(parts[2]) < 2 {
+		return lane, fmt.Errorf("%w Invalid lane %q", ErrInvalidPosition, s)
+	}
+
+	for i := range lane.Cards {
+		cards, err := parseDeck(parts[i])
+		if err != nil {
+			return lane, err
+		}
+		lane.Cards[i] = cards
+	}
+
+	status := parts[2]
+	claimed, okClaimed := parsePlayerMark(status[0])
+	completedFirst, okCompleted := parsePlayerMark(status[1])
+	if !okClaimed || !okCompleted {
+		return lane, fmt.Errorf("%w Invalid lane status %q", ErrInvalidPosition, status)
+	}
+	lane.Claimed, lane.CompletedFirst = claimed, completedFirst
+
+	flags := status[2:]
+	for len(flags) > 0 {
+		tactic := TacticNone
+		for t, flag := range environmentFlags {
+			if rune(flags[0]) == flag {
+				tactic = t
+			}
+		}
+		owner, ok := 0, false
+		if len(flags) >= 2 {
+			owner, ok = parsePlayerMark(flags[1])
+		}
+		if tactic == TacticNone || !ok || owner == 0 || lane.Environment[0].FindCardIdx(Card{Tactic: tactic}) != -1 ||
+			lane.Environment[1].FindCardIdx(Card{Tactic: tactic}) != -1 {
+			return lane, fmt.Errorf("%w Invalid lane status %q", ErrInvalidPosition, status)
+		}
+		lane.Environment[owner-1] = append(lane.Environment[owner-1], Card{Tactic: tactic})
+		flags = flags[2:]
+	}
+	lane.UpdateEnvironment()
+
+	for i, side := range lane.Cards {
+		if len(side) > lane.MaxCards() {
+			return lane, fmt.Errorf("%w Too many cards in lane %q", ErrInvalidPosition, s)
+		}
+		for _, c := range side {
+			if c.IsEnvironment() || c.IsGuile() {
+				return lane, fmt.Errorf("%w %s cannot be in a lane", ErrInvalidPosition, c)
+			}
+		}
+		if lane.CompletedFirst == i+1 && !lane.IsSideComplete(i) {
+			return lane, fmt.Errorf("%w Side %d of lane %q is not complete", ErrInvalidPosition, i+1, s)
+		}
+	}
+	return lane, nil
+}
+
+// Decodes a position written by Position and checks that it could occur in a game.
+// Claimable lanes are recalculated for the active player.
+func ParsePosition(s string) (*GameState, error) {
+	fields := strings.Fields(s)
+	if len(fields) != positionFields {
+		return nil, fmt.Errorf("%w Expected %d fields, got %d", ErrInvalidPosition, positionFields, len(fields))
+	}
+
+	gs := &GameState{}
+
+	laneFields := strings.Split(fields[0], "/")
+	if len(laneFields) != len(gs.Lanes) {
+		return nil, fmt.Errorf("%w Expected %d lanes, got %d", ErrInvalidPosition, len(gs.Lanes), len(laneFields))
+	}
+	for i, field := range laneFields {
+		lane, err := parseLaneField(field)
+		if err != nil {
+			return nil, fmt.Errorf("lane %d: %w", i+1, err)
+		}
+		gs.Lanes[i] = lane
+	}
+
+	decks := []*Deck{&gs.PlayerHands[0], &gs.PlayerHands[1], &gs.TroopDeck, &gs.TacticsDeck, &gs.DiscardPile}
+	for i, deck := range decks {
+		parsed, err := parseDeck(fields[1+i])
+		if err != nil {
+			return nil, err
+		}
+		*deck = parsed
+	}
+
+	switch fields[6] {
+	case "1", "2":
+		gs.ActivePlayer = int(fields[6][0] - '1')
+	default:
+		return nil, fmt.Errorf("%w Invalid active player %q", ErrInvalidPosition, fields[6])
+	}
+
+	phase, phaseFound := ParseTurnPhase(fields[7])
+	if !phaseFound {
+		return nil, fmt.Errorf("%w Invalid turn phase %q", ErrInvalidPosition, fields[7])
+	}
+	gs.TurnPhase = phase
+
+	var err error
+	if gs.TacticsPlayed, err = parsePair(fields[8]); err != nil {
+		return nil, err
+	}
+	scout, err := parsePair(fields[9])
+	if err != nil {
+		return nil, err
+	}
+	gs.ScoutDrawsLeft, gs.ScoutReturnsLeft = scout[0], scout[1]
+
+	passes, err := strconv.Atoi(fields[10])
+	if err != nil || passes < 0 {
+		return nil, fmt.Errorf("%w Invalid passes %q", ErrInvalidPosition, fields[10])
+	}
+	gs.ConsecutivePasses = passes
+
+	turn, err := strconv.Atoi(fields[11])
+	if err != nil || turn < 1 {
+		return nil, fmt.Errorf("%w Invalid turn %q", ErrInvalidPosition, fields[11])
+	}
+	gs.Turn = turn - 1
+
+	if err := gs.validatePosition(); err != nil {
+		return nil, err
+	}
+	gs.UpdateClaimableLanes(gs.ActivePlayer)
+	return gs, nil
+}
+
+// Every card must be in exactly one place
+func (gs *GameState) validatePosition() error {
+	seen := map[Card]bool{}
+	decks := []Deck{gs.PlayerHands[0], gs.PlayerHands[1], gs.TroopDeck, gs.TacticsDeck, gs.DiscardPile}
+	for _, lane := range gs.Lanes {
+		decks = append(decks, lane.Cards[0], lane.Cards[1], lane.Environment[0], lane.Environment[1])
+	}
+	for _, deck := range decks {
+		for _, c := range deck {
+			if seen[c] {
+				return fmt.Errorf("%w %s appears more than once", ErrInvalidPosition, c)
+			}
+			seen[c] = true
+		}
+	}
+	for _, c := range append(CreateTroopDeck(), CreateTacticsDeck()...) {
+		if !seen[c] {
+			return fmt.Errorf("%w %s is missing", ErrInvalidPosition, c)
+		}
+	}
+
+	if gs.TroopDeck.TacticsCount() > 0 || gs.TacticsDeck.TacticsCount() != len(gs.TacticsDeck) {
+		return fmt.Errorf("%w Cards are in the wrong deck", ErrInvalidPosition)
+	}
+
+	for playerIdx := range gs.PlayerHands {
+		leaders := 0
+		for _, lane := range gs.Lanes {
+			for _, c := range lane.Cards[playerIdx] {
+				if c.IsLeader() {
+					leaders++
+				}
+			}
+		}
+		if leaders > 1 {
+			return fmt.Errorf("%w Player %d has more than one leader on the board", ErrInvalidPosition, playerIdx+1)
+		}
+	}
+
+	if gs.ScoutDrawsLeft > ScoutDraws || gs.ScoutReturnsLeft > ScoutReturns {
+		return fmt.Errorf("%w Too many scout draws or returns left", ErrInvalidPosition)
+	}
+	return nil
+}
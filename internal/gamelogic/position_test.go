@@ -0,0 +1,90 @@
+package gamelogic
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+func TestPositionRoundTrip(t *testing.T) {
+	gs := NewGameStateWithSeed(7)
+	rng := rand.New(rand.NewPCG(7, 7))
+
+	for !gs.IsOver() {
+		position := gs.Position()
+		parsed, err := ParsePosition(position)
+		if err != nil {
+			t.Fatalf("ParsePosition(%q) error = %v", position, err)
+		}
+		if parsed.Position() != position {
+			t.Fatalf("position does not round trip:\n%s\n%s", position, parsed.Position())
+		}
+
+		want := gs.LegalMoves(gs.ActivePlayer)
+		if got := parsed.LegalMoves(parsed.ActivePlayer); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("LegalMoves() = %v, want %v in %q", got, want, position)
+		}
+
+		gs.ExecutePlayerMove(gs.ActivePlayer, &want[rng.IntN(len(want))])
+	}
+}
+
+func TestParsePositionErrors(t *testing.T) {
+	valid := NewGameStateWithSeed(1).Position()
+	fields := strings.Fields(valid)
+	lanes := strings.Split(fields[0], "/")
+
+	replaceField := func(idx int, value string) string {
+		f := append([]string{}, fields...)
+		f[idx] = value
+		return strings.Join(f, " ")
+	}
+	replaceLane := func(value string) string {
+		l := append([]string{}, lanes...)
+		l[0] = value
+		return replaceField(0, strings.Join(l, "/"))
+	}
+	hand := strings.Split(fields[1], ",")
+
+	tests := map[string]string{
+		"missing field":     strings.Join(fields[1:], " "),
+		"missing lane":      replaceField(0, strings.Join(lanes[1:], "/")),
+		"unknown card":      replaceField(1, "X1"),
+		"duplicate card":    replaceField(1, fields[1]+","+hand[0]),
+		"missing card":      replaceField(1, strings.Join(hand[1:], ",")),
+		"active player":     replaceField(6, "3"),
+		"turn phase":        replaceField(7, "attack"),
+		"scout":             replaceField(9, "4,0"),
+		"turn":              replaceField(11, "0"),
+		"lane status":       replaceLane("-|-|3-"),
+		"incomplete side":   replaceLane("-|-|-1"),
+		"fog card twice":    replaceLane("-|-|--f"),
+		"too many cards":    replaceLane("AL,DA,CC,SB|-|--"),
+		"guile card placed": replaceLane("SC|-|--"),
+	}
+	for name, position := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParsePosition(position); !errors.Is(err, ErrInvalidPosition) && !errors.Is(err, ErrInvalidNotation) {
+				t.Errorf("ParsePosition(%q) error = %v", position, err)
+			}
+		})
+	}
+}
+
+// The example in the Position documentation must stay in sync with the encoder
+func TestDocumentedPosition(t *testing.T) {
+	const position = "SB,B6|-|--/CC|G7|--/R5,B3|O4,P7|--f2/G3,R3|-|--/-|G4|--/P9|-|--/P6,B1|R4,B9|--/AL|R2,Y4,Y8|-2/G8,O7,Y7|Y3,O2,G2|12 Y1,P1,O3,B8,G5,B5,P5 O5,DE,DA,MU,B4,SC,O9 R6,Y9,O10,R9,B2,Y6,O6,R10,R1,P8,O1,B10,Y2,G9,P10,P2,B7,Y5,R7,P3,G1,Y10,O8,P4,G10,G6,R8 - RE,TR 2 placement 3,3 0,0 0 30"
+
+	gs, err := ParsePosition(position)
+	if err != nil {
+		t.Fatalf("ParsePosition() error = %v", err)
+	}
+	if gs.Position() != position {
+		t.Errorf("Position() = %q, want %q", gs.Position(), position)
+	}
+	if lane := gs.Lanes[8]; lane.Claimed != ClaimedByPlayerOne || lane.CompletedFirst != CompletedByPlayerTwo {
+		t.Errorf("lane 9 claimed = %d, completed first = %d", lane.Claimed, lane.CompletedFirst)
+	}
+}
@@ -0,0 +1,118 @@
+package gameserver
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/it-ankka/battleline/internal/bot"
+	"github.com/it-ankka/battleline/internal/gamelogic"
+)
+
+// Bots wait a little before each move so the other player can follow the game
+const (
+	botMinMoveDelay    = 600 * time.Millisecond
+	botMoveDelayJitter = 900 * time.Millisecond
+)
+
+func NewBotClient(index int, difficulty bot.Difficulty) (*SessionClient, error) {
+	client, err := NewClient(index)
+	if err != nil {
+		return nil, err
+	}
+
+	client.Bot = bot.NewDifficultyBot(difficulty, rand.Uint64())
+	client.IsBot = true
+	client.Nickname = fmt.Sprintf("Computer (%s)", difficulty)
+	client.Connected = true
+	client.Ready = true
+	client.botWake = make(chan struct{}, 1)
+	client.botRejected = make(chan struct{}, 1)
+
+	return client, nil
+}
+
+func (game *GameSession) AddBotClient(difficulty bot.Difficulty) (*SessionClient, error) {
+	game.mu.Lock()
+	defer game.mu.Unlock()
+
+	if game.Clients[1] != nil || game.Status != SessionStatusCreated {
+		return nil, errors.New("Game is full.")
+	}
+	client, err := NewBotClient(1, difficulty)
+	if err != nil {
+		return nil, errors.New("Unable to add bot to session.")
+	}
+	game.Clients[1] = client
+
+	go client.RunBot(game)
+	return client, nil
+}
+
+// Never blocks, since sessions broadcast while holding their lock
+func (client *SessionClient) notifyBot() {
+	select {
+	case client.botWake <- struct{}{}:
+	default:
+	}
+}
+
+// Lets the bot choose again when the session drops its move. Never blocks either.
+func (client *SessionClient) rejectBotMove() {
+	select {
+	case client.botRejected <- struct{}{}:
+	default:
+	}
+}
+
+// Plays the bot's moves until the session ends. The bot looks at the same private game
+// state a human player gets and submits its moves through the session like a client.
+func (client *SessionClient) RunBot(game *GameSession) {
+	// Number of game events when the last move was submitted
+	submittedAt := -1
+
+	for {
+		select {
+		case <-client.botWake:
+		case <-client.botRejected:
+			// The move was not played, so the same position is searched again
+			submittedAt = -1
+		case <-game.done:
+			return
+		}
+
+		game.mu.RLock()
+		status, gs := game.Status, game.GameState
+		var state *gamelogic.PrivateGameState
+		if gs != nil && status == SessionStatusInProgress && gs.ActivePlayer == client.Index && len(gs.Events) != submittedAt {
+			state = gs.GetPrivateGameState(client.Index)
+			submittedAt = len(gs.Events)
+		}
+		game.mu.RUnlock()
+
+		if status == SessionStatusEnded {
+			return
+		}
+		// Not the bot's turn, or the last move has not been processed yet
+		if state == nil || len(state.LegalMoves) == 0 {
+			continue
+		}
+
+		start := time.Now()
+		move := client.Bot.ChooseMove(state)
+		time.Sleep(botMinMoveDelay + rand.N(botMoveDelayJitter) - time.Since(start))
+
+		slog.Debug("Bot move", slog.String("clientId", client.ID), slog.String("move", move.String()))
+		select {
+		case game.messages <- ClientMessage{
+			Client:      client,
+			MessageType: ClientMessageMove,
+			Data:        &ClientMessageData{Move: &move},
+		}:
+		case <-game.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package gameserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/it-ankka/battleline/internal/bot"
+	"github.com/it-ankka/battleline/internal/gamelogic"
+)
+
+// Runs the bot, closes the session after the delay and fails if the bot does not stop
+func runBotUntilClosed(t *testing.T, game *GameSession, client *SessionClient, delay time.Duration) {
+	t.Helper()
+	stopped := make(chan struct{})
+	go func() {
+		client.RunBot(game)
+		close(stopped)
+	}()
+
+	client.notifyBot()
+	time.Sleep(delay)
+	game.Close()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunBot() did not stop after the session was closed")
+	}
+}
+
+func TestRunBotStopsWhenWaiting(t *testing.T) {
+	game, err := NewGameSession()
+	if err != nil {
+		t.Fatalf("NewGameSession() error = %v", err)
+	}
+	client, err := NewBotClient(1, bot.DifficultyEasy)
+	if err != nil {
+		t.Fatalf("NewBotClient() error = %v", err)
+	}
+	game.Clients[1] = client
+
+	runBotUntilClosed(t, game, client, 0)
+}
+
+func TestRunBotStopsWhenSending(t *testing.T) {
+	game, err := NewGameSession()
+	if err != nil {
+		t.Fatalf("NewGameSession() error = %v", err)
+	}
+	game.GameState = gamelogic.NewGameStateWithSeed(1)
+	game.Status = SessionStatusInProgress
+	// Nothing reads the session's messages, so the bot blocks once it has chosen a move
+	client, err := NewBotClient(game.GameState.ActivePlayer, bot.DifficultyEasy)
+	if err != nil {
+		t.Fatalf("NewBotClient() error = %v", err)
+	}
+	game.Clients[client.Index] = client
+
+	runBotUntilClosed(t, game, client, botMinMoveDelay+botMoveDelayJitter+time.Second)
+}
+
+func TestCloseTwice(t *testing.T) {
+	game, err := NewGameSession()
+	if err != nil {
+		t.Fatalf("NewGameSession() error = %v", err)
+	}
+	game.Close()
+	game.Close()
+}
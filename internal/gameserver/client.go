@@ -8,6 +8,7 @@ import (
 
 	"github.com/coder/websocket"
 	"github.com/coder/websocket/wsjson"
+	"github.com/it-ankka/battleline/internal/bot"
 	"github.com/it-ankka/battleline/internal/gameutils"
 )
 
@@ -17,11 +18,17 @@ type SessionClient struct {
 	send       chan SessionMessage
 	cancel     context.CancelFunc
 
+	// Set for server-side bots, which have no connection
+	Bot         bot.Strategy `json:"-"`
+	botWake     chan struct{}
+	botRejected chan struct{}
+
 	ID        string `json:"playerId"`
 	Index     int    `json:"playerIndex"`
 	Nickname  string `json:"nickname"`
 	Connected bool   `json:"connected"`
 	Ready     bool   `json:"ready"`
+	IsBot     bool   `json:"isBot"`
 }
 
 func NewClient(index int) (*SessionClient, error) {
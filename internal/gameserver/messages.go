@@ -3,9 +3,11 @@ package gameserver
 import (
 	"context"
 	"log/slog"
+	"math/rand/v2"
 	"time"
 
 	"github.com/coder/websocket/wsjson"
+	"github.com/it-ankka/battleline/internal/bot"
 	"github.com/it-ankka/battleline/internal/gamelogic"
 )
 
@@ -15,13 +17,19 @@ type ClientMessageType string
 const (
 	ClientMessageSetReady ClientMessageType = "set_ready"
 	ClientMessageMove     ClientMessageType = "move"
+	ClientMessageHint     ClientMessageType = "hint"
 	ClientMessageChat     ClientMessageType = "chat"
 	ClientMessageClose    ClientMessageType = "close"
+	// Hints computed off the session goroutine, posted back by the server itself
+	clientMessageHintResult ClientMessageType = "hint_result"
 
 	SessionMessagePing  SessionMessageType = "ping"
 	SessionMessageSync  SessionMessageType = "sync"
 	SessionMessageError SessionMessageType = "error"
 	SessionMessageClose SessionMessageType = "close"
+	SessionMessageHint  SessionMessageType = "hint"
+	// Sent to a player whose move was much worse than the best one
+	SessionMessageBlunder SessionMessageType = "blunder"
 
 	SessionMessageSessionStart SessionMessageType = "session_start"
 	SessionMessageSessionEnd   SessionMessageType = "session_end"
@@ -45,6 +53,7 @@ type SessionMessage struct {
 	GameState   *gamelogic.PrivateGameState `json:"state"`
 	SessionInfo *GameSessionSnapshot        `json:"session"`
 	Error       *SessionError               `json:"error"`
+	Hints       []bot.Hint                  `json:"hints,omitempty"`
 }
 
 type ClientMessageData struct {
@@ -57,6 +66,11 @@ type ClientMessage struct {
 	Client      *SessionClient
 	MessageType ClientMessageType  `json:"type"`
 	Data        *ClientMessageData `json:"data"`
+
+	// Set only by the server, since unexported fields are never decoded
+	hints     []bot.Hint
+	hintType  SessionMessageType
+	hintEvent int
 }
 
 func (game *GameSession) IsValidMessage(m ClientMessage) bool {
@@ -70,6 +84,14 @@ func (game *GameSession) IsValidMessage(m ClientMessage) bool {
 			game.Status == SessionStatusInProgress &&
 			game.GameState.ActivePlayer == m.Client.Index &&
 			game.GameState.IsValidPlayerMove(m.Client.Index, m.Data.Move)
+	case ClientMessageClose:
+		return true
+	case clientMessageHintResult:
+		return m.hints != nil
+	case ClientMessageHint:
+		return game.HintsEnabled &&
+			game.Status == SessionStatusInProgress &&
+			game.GameState.ActivePlayer == m.Client.Index
 	default:
 		return false
 	}
@@ -80,25 +102,54 @@ func (client *SessionClient) SendSessionMessage(
 	game *GameSession,
 	error *SessionError,
 ) {
+	// Bots read the game state themselves when notified
+	if client.Bot != nil {
+		client.notifyBot()
+		return
+	}
+	if client.Connection == nil {
+		slog.Error("Could not connect to client", slog.String("clientId", client.ID))
+		return
+	}
+
+	message := client.newSessionMessage(messageType, game)
+	if error != nil {
+		message.Error = error
+	}
+
+	wsjson.Write(context.Background(), client.Connection, message)
+}
+
+// Hints are only sent to the player they are meant for
+func (client *SessionClient) SendHints(game *GameSession, messageType SessionMessageType, hints []bot.Hint) {
 	if client.Connection == nil {
 		slog.Error("Could not connect to client", slog.String("clientId", client.ID))
 		return
 	}
 
+	message := client.newSessionMessage(messageType, game)
+	message.Hints = hints
+
+	wsjson.Write(context.Background(), client.Connection, message)
+}
+
+func (client *SessionClient) newSessionMessage(messageType SessionMessageType, game *GameSession) SessionMessage {
 	message := SessionMessage{MessageType: messageType, Timestamp: time.Now(), ClientIdx: client.Index}
 	if game != nil {
 		message.SessionInfo = game.Snapshot()
 	}
 
 	if game != nil && game.GameState != nil {
-		message.GameState = game.GameState.GetPrivateGameState(client.Index)
-	}
-
-	if error != nil {
-		message.Error = error
+		if game.Status == SessionStatusEnded {
+			message.GameState = game.GameState.GetRevealedGameState(client.Index)
+		} else {
+			message.GameState = game.GameState.GetPrivateGameState(client.Index)
+		}
+		if game.TrainingWheels && game.Status == SessionStatusInProgress {
+			message.GameState.LaneWinProbabilities = game.cachedLaneWinProbabilities(client.Index)
+		}
 	}
-
-	wsjson.Write(context.Background(), client.Connection, message)
+	return message
 }
 
 func (game *GameSession) Broadcast(messageType SessionMessageType) {
@@ -128,10 +179,126 @@ func (game *GameSession) HandleClientSetReadyMessage(m ClientMessage) {
 
 func (game *GameSession) HandleClientMoveMessage(m ClientMessage) {
 	game.mu.Lock()
-	defer game.mu.Unlock()
+	// Blunders are checked from the position the move was played in
+	var before *gamelogic.PrivateGameState
+	if game.HintsEnabled && !m.Client.IsBot {
+		before = game.GameState.GetPrivateGameState(m.Client.Index)
+	}
 
 	game.GameState.ExecutePlayerMove(m.Client.Index, m.Data.Move)
+	ended := game.GameState.IsOver()
+	if ended {
+		game.Status = SessionStatusEnded
+		slog.Info("Game ended", slog.String("gameId", game.ID), slog.Int("winner", game.GameState.Winner()))
+	}
+	seed, eventCount := game.GameState.Seed, len(game.GameState.Events)
+	game.mu.Unlock()
+
+	if ended {
+		game.Broadcast(SessionMessageSessionEnd)
+		game.Close()
+		return
+	}
+	game.updateLaneWinProbabilities()
 	game.Broadcast(SessionMessageClientMove)
+
+	if before != nil {
+		move := *m.Data.Move
+		rng := rand.New(rand.NewPCG(seed, uint64(eventCount)))
+		game.computeHints(m.Client, SessionMessageBlunder, eventCount, func() []bot.Hint {
+			if better, ok := bot.Blunder(before, move, rng); ok {
+				return []bot.Hint{better}
+			}
+			return nil
+		})
+	}
+}
+
+// Number of random deals behind each lane win probability in training wheels mode
+const laneWinSamples = 64
+
+// Estimates the lane win probabilities of both players for training wheels mode. They
+// are slow to compute, so they are computed once for each position, before the
+// position is sent and without holding the session lock. The random deals are seeded
+// from the game, so every position always gets the same estimates.
+func (game *GameSession) updateLaneWinProbabilities() {
+	game.mu.RLock()
+	if !game.TrainingWheels || game.Status != SessionStatusInProgress || game.GameState == nil {
+		game.mu.RUnlock()
+		return
+	}
+	seed, eventCount := game.GameState.Seed, len(game.GameState.Events)
+	// Computed from each player's own view so the estimates reveal nothing hidden
+	states := [2]*gamelogic.PrivateGameState{}
+	for playerIdx := range states {
+		if client := game.Clients[playerIdx]; client != nil && client.Bot == nil {
+			states[playerIdx] = game.GameState.GetPrivateGameState(playerIdx)
+		}
+	}
+	game.mu.RUnlock()
+
+	game.laneWinMu.Lock()
+	defer game.laneWinMu.Unlock()
+	if game.laneWinEvents == eventCount {
+		return
+	}
+	game.laneWinEvents = eventCount
+	for playerIdx, state := range states {
+		game.laneWin[playerIdx] = nil
+		if state != nil {
+			rng := rand.New(rand.NewPCG(seed+uint64(eventCount), uint64(playerIdx)))
+			game.laneWin[playerIdx] = bot.LaneWinProbabilities(state, playerIdx, laneWinSamples, rng)
+		}
+	}
+}
+
+// The estimates for the current position, or nil if they have not been computed
+func (game *GameSession) cachedLaneWinProbabilities(playerIdx int) []float64 {
+	game.laneWinMu.Lock()
+	defer game.laneWinMu.Unlock()
+	if game.laneWinEvents != len(game.GameState.Events) {
+		return nil
+	}
+	return game.laneWin[playerIdx]
+}
+
+// Number of suggestions sent for each hint request
+const hintCount = 3
+
+func (game *GameSession) HandleClientHintMessage(m ClientMessage) {
+	game.mu.Lock()
+	game.HintsUsed[m.Client.Index]++
+	state := game.GameState.GetPrivateGameState(m.Client.Index)
+	eventCount := len(game.GameState.Events)
+	game.mu.Unlock()
+
+	game.computeHints(m.Client, SessionMessageHint, eventCount, func() []bot.Hint {
+		return bot.Hints(state, hintCount)
+	})
+}
+
+// Searches for hints in their own goroutine and posts them back to the session, so
+// moves keep being processed during the search. Nil results are not sent.
+func (game *GameSession) computeHints(client *SessionClient, messageType SessionMessageType, eventCount int, compute func() []bot.Hint) {
+	go func() {
+		hints := compute()
+		if hints == nil {
+			return
+		}
+		select {
+		case game.messages <- ClientMessage{Client: client, MessageType: clientMessageHintResult, hints: hints, hintType: messageType, hintEvent: eventCount}:
+		case <-game.done:
+		}
+	}()
+}
+
+// Hints for a position the game has already left are dropped, but a blunder is always
+// about a move that has been played
+func (game *GameSession) HandleHintResultMessage(m ClientMessage) {
+	if m.hintType == SessionMessageHint && m.hintEvent != len(game.GameState.Events) {
+		return
+	}
+	m.Client.SendHints(game, m.hintType, m.hints)
 }
 
 func (game *GameSession) HandleClientChatMessage(m ClientMessage) {
@@ -144,8 +311,10 @@ func (game *GameSession) HandleClientChatMessage(m ClientMessage) {
 	game.Broadcast(SessionMessageClientChat)
 }
 
-// TODO
+// A player leaving tears the session down
 func (game *GameSession) HandleClientCloseMessage(m ClientMessage) {
+	game.Broadcast(SessionMessageClose)
+	game.Close()
 }
 
 func (game *GameSession) ProcessClientMessage(m ClientMessage) {
@@ -154,6 +323,9 @@ func (game *GameSession) ProcessClientMessage(m ClientMessage) {
 
 	if !game.IsValidMessage(m) {
 		slog.Error("Unable to process client message.", slog.String("clientId", m.Client.ID))
+		if m.Client.Bot != nil {
+			m.Client.rejectBotMove()
+		}
 		return
 	}
 
@@ -162,10 +334,14 @@ func (game *GameSession) ProcessClientMessage(m ClientMessage) {
 		game.HandleClientSetReadyMessage(m)
 	case ClientMessageMove:
 		game.HandleClientMoveMessage(m)
+	case ClientMessageHint:
+		game.HandleClientHintMessage(m)
 	case ClientMessageChat:
 		game.HandleClientChatMessage(m)
 	case ClientMessageClose:
 		game.HandleClientCloseMessage(m)
+	case clientMessageHintResult:
+		game.HandleHintResultMessage(m)
 	default:
 		slog.Error("Unable to process client message.", slog.String("clientId", m.Client.ID))
 		return
@@ -0,0 +1,47 @@
+package gameserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/it-ankka/battleline/internal/gamelogic"
+)
+
+func TestHintsArePostedBackToTheSession(t *testing.T) {
+	game, err := NewGameSession()
+	if err != nil {
+		t.Fatalf("NewGameSession() error = %v", err)
+	}
+	defer game.Close()
+	game.GameState = gamelogic.NewGameStateWithSeed(1)
+	game.Status = SessionStatusInProgress
+	game.HintsEnabled = true
+	client := game.Clients[0]
+	client.Index = game.GameState.ActivePlayer
+
+	// The handler returns before the hints are ready and holds no lock afterwards
+	game.HandleClientHintMessage(ClientMessage{Client: client, MessageType: ClientMessageHint})
+	game.mu.Lock()
+	used := game.HintsUsed[client.Index]
+	game.mu.Unlock()
+	if used != 1 {
+		t.Errorf("HintsUsed = %d, want 1", used)
+	}
+
+	select {
+	case m := <-game.messages:
+		if m.MessageType != clientMessageHintResult || m.hintType != SessionMessageHint || len(m.hints) == 0 {
+			t.Errorf("posted message = %+v, want hints", m)
+		}
+		if !game.IsValidMessage(m) {
+			t.Errorf("IsValidMessage() = false for the posted hints")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no hints were posted back to the session")
+	}
+
+	// Clients cannot post hint results of their own
+	if game.IsValidMessage(ClientMessage{Client: client, MessageType: clientMessageHintResult}) {
+		t.Errorf("IsValidMessage() = true for a hint result without hints")
+	}
+}
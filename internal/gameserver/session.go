@@ -3,9 +3,12 @@ package gameserver
 import (
 	"errors"
 	"log/slog"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/it-ankka/battleline/internal/analysis"
+	"github.com/it-ankka/battleline/internal/bot"
 	"github.com/it-ankka/battleline/internal/gamelogic"
 	"github.com/it-ankka/battleline/internal/gameutils"
 )
@@ -26,21 +29,49 @@ type GameSession struct {
 	CreatedAt time.Time         `json:"createdAt"`
 	ChatLog   []*ChatMessage    `json:"chatLog"`
 
+	// Hints are optional and every request is counted for each player
+	HintsEnabled bool   `json:"hintsEnabled"`
+	HintsUsed    [2]int `json:"hintsUsed"`
+	// Shows each player their estimated chance of winning every lane
+	TrainingWheels bool `json:"trainingWheels"`
+
 	GameState *gamelogic.GameState
 
-	// Channels for communication
-	messages chan ClientMessage
-	done     chan struct{}
+	// Lane win probabilities of both players in the position after laneWinEvents events
+	laneWinMu     sync.Mutex
+	laneWinEvents int
+	laneWin       [2][]float64
+
+	// The post-game analysis is computed on the first request
+	analysisOnce  sync.Once
+	analysis      *analysis.Report
+	analysisError error
+
+	// Channels for communication. Done is closed when the session is over.
+	messages  chan ClientMessage
+	done      chan struct{}
+	closeOnce sync.Once
 
 	mu sync.RWMutex
 }
 
 type GameSessionSnapshot struct {
+	ID           string            `json:"id"`
+	Status       SessionStatus     `json:"status"`
+	CreatedAt    time.Time         `json:"createdAt"`
+	Clients      [2]*SessionClient `json:"clients"`
+	ChatLog      []*ChatMessage    `json:"chatLog"`
+	HintsEnabled bool              `json:"hintsEnabled"`
+	HintsUsed    [2]int            `json:"hintsUsed"`
+
+	TrainingWheels bool `json:"trainingWheels"`
+}
+
+type GameEventLog struct {
 	ID        string            `json:"id"`
-	Status    SessionStatus     `json:"status"`
-	CreatedAt time.Time         `json:"createdAt"`
-	Clients   [2]*SessionClient `json:"clients"`
-	ChatLog   []*ChatMessage    `json:"chatLog"`
+	Seed      uint64            `json:"seed,string"`
+	Events    []gamelogic.Event `json:"events"`
+	HintsUsed [2]int            `json:"hintsUsed"`
 }
 
 func NewGameSession() (*GameSession, error) {
@@ -57,6 +88,8 @@ func NewGameSession() (*GameSession, error) {
 		ChatLog:   []*ChatMessage{},
 		messages:  make(chan ClientMessage),
 		done:      make(chan struct{}),
+
+		laneWinEvents: -1,
 	}
 
 	client, err := NewClient(0)
@@ -77,6 +110,11 @@ func (game *GameSession) Snapshot() *GameSessionSnapshot {
 		CreatedAt: game.CreatedAt,
 		Clients:   game.Clients,
 		ChatLog:   game.ChatLog,
+
+		HintsEnabled: game.HintsEnabled,
+		HintsUsed:    game.HintsUsed,
+
+		TrainingWheels: game.TrainingWheels,
 	}
 }
 
@@ -114,7 +152,7 @@ func (game *GameSession) IsReadyToStart() bool {
 		return false
 	}
 	for _, client := range game.Clients {
-		if client == nil || client.Connection == nil || client.Ready == false {
+		if client == nil || (client.Connection == nil && client.Bot == nil) || client.Ready == false {
 			return false
 		}
 	}
@@ -123,12 +161,23 @@ func (game *GameSession) IsReadyToStart() bool {
 
 func (game *GameSession) StartGame() {
 	game.mu.Lock()
-	defer game.mu.Unlock()
 	game.GameState = gamelogic.NewGameState()
 	game.Status = SessionStatusInProgress
+	// The seed is enough to rebuild the deal when reproducing bugs
+	slog.Info("Game started", slog.String("gameId", game.ID), slog.Uint64("seed", game.GameState.Seed))
+	game.mu.Unlock()
+
+	game.updateLaneWinProbabilities()
 	game.Broadcast(SessionMessageSessionStart)
 }
 
+// Stops the session's listener and bots. Safe to call more than once.
+func (game *GameSession) Close() {
+	game.closeOnce.Do(func() {
+		close(game.done)
+	})
+}
+
 func (game *GameSession) Listen() {
 	slog.Info("Game listening", slog.String("gameId", game.ID))
 	func() {
@@ -143,6 +192,67 @@ func (game *GameSession) Listen() {
 			game.ProcessClientMessage(message)
 		case <-game.done:
 			slog.Info("Game closed", slog.String("gameId", game.ID))
+			return
+		}
+	}
+}
+
+// The event log reveals both hands, so it is only available once the game has ended
+func (game *GameSession) EventLog() (*GameEventLog, error) {
+	game.mu.RLock()
+	defer game.mu.RUnlock()
+
+	if game.Status != SessionStatusEnded || game.GameState == nil {
+		return nil, errors.New("Game has not ended.")
+	}
+
+	return &GameEventLog{
+		ID:     game.ID,
+		Seed:   game.GameState.Seed,
+		Events: game.GameState.Events,
+
+		HintsUsed: game.HintsUsed,
+	}, nil
+}
+
+// The record of an ended game, with the players' nicknames and the hints they used
+func (game *GameSession) GameRecord() (*gamelogic.GameRecord, error) {
+	game.mu.RLock()
+	defer game.mu.RUnlock()
+
+	if game.Status != SessionStatusEnded || game.GameState == nil {
+		return nil, errors.New("Game has not ended.")
+	}
+
+	headers := map[string]string{}
+	for playerIdx, key := range []string{gamelogic.HeaderPlayer1, gamelogic.HeaderPlayer2} {
+		if client := game.Clients[playerIdx]; client != nil && client.Nickname != "" {
+			headers[key] = client.Nickname
 		}
 	}
+	if game.HintsEnabled {
+		headers[gamelogic.HeaderHints1] = strconv.Itoa(game.HintsUsed[0])
+		headers[gamelogic.HeaderHints2] = strconv.Itoa(game.HintsUsed[1])
+	}
+	return gamelogic.NewGameRecord(game.GameState, game.CreatedAt, headers), nil
+}
+
+// Settings for the post-game analysis, which takes a few seconds for a full game
+const analysisSamples = 128
+
+var analysisBudget = bot.DifficultyEasy.Budget()
+
+// Like the event log, the analysis is only available once the game has ended
+func (game *GameSession) Analysis() (*analysis.Report, error) {
+	eventLog, err := game.EventLog()
+	if err != nil {
+		return nil, err
+	}
+
+	game.analysisOnce.Do(func() {
+		start := time.Now()
+		game.analysis, game.analysisError = analysis.Analyze(eventLog.Seed, eventLog.Events, analysisSamples, analysisBudget)
+		slog.Info("Game analyzed", slog.String("gameId", game.ID), slog.Duration("duration", time.Since(start)))
+	})
+	return game.analysis, game.analysisError
 }
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/coder/websocket"
+	"github.com/it-ankka/battleline/internal/bot"
 	. "github.com/it-ankka/battleline/internal/gameserver"
 )
 
@@ -83,12 +84,38 @@ func JoinGameHandler(s *GameServer) http.HandlerFunc {
 
 func CreateGameHandler(a *GameServer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// Games against the computer fill the second seat with a bot, e.g. ?opponent=bot&difficulty=hard.
+		// Move hints are enabled with ?hints=true and lane win estimates with ?trainingWheels=true.
+		difficulty := bot.DifficultyMedium
+		opponent := r.URL.Query().Get("opponent")
+		if name := r.URL.Query().Get("difficulty"); name != "" {
+			var ok bool
+			if difficulty, ok = bot.ParseDifficulty(name); !ok {
+				http.Error(w, "Unknown difficulty: "+name, 400)
+				return
+			}
+		}
+		if opponent != "" && opponent != "human" && opponent != "bot" {
+			http.Error(w, "Unknown opponent: "+opponent, 400)
+			return
+		}
+
 		game, err := a.GameManager.CreateGame()
 		if err != nil {
 			slog.Error("Game creation failed", slog.Any("error", err.Error()))
 			http.Error(w, "Failed to create game", 500)
+			return
+		}
+		game.HintsEnabled = r.URL.Query().Get("hints") == "true"
+		game.TrainingWheels = r.URL.Query().Get("trainingWheels") == "true"
+		if opponent == "bot" {
+			if _, err := game.AddBotClient(difficulty); err != nil {
+				slog.Error("Adding bot failed", slog.Any("error", err.Error()))
+				http.Error(w, "Failed to create game", 500)
+				return
+			}
 		}
-		slog.Info("Game Created", slog.String("gameId", game.ID))
+		slog.Info("Game Created", slog.String("gameId", game.ID), slog.String("opponent", opponent))
 		addClientCookies(w, game.Clients[0].ID, game.Clients[0].Key)
 
 		w.Header().Set("Content-Type", "application/json")
@@ -135,3 +162,66 @@ func ConnectHandler(s *GameServer) http.HandlerFunc {
 
 	}
 }
+
+func GameEventsHandler(s *GameServer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gameId := r.PathValue("gameId")
+		game, exists := s.GameManager.GetGame(gameId)
+		if !exists {
+			http.Error(w, "Game not found with ID: "+gameId, 404)
+			return
+		}
+
+		eventLog, err := game.EventLog()
+		if err != nil {
+			http.Error(w, err.Error(), 403)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(eventLog)
+	}
+}
+
+func GameRecordHandler(s *GameServer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gameId := r.PathValue("gameId")
+		game, exists := s.GameManager.GetGame(gameId)
+		if !exists {
+			http.Error(w, "Game not found with ID: "+gameId, 404)
+			return
+		}
+
+		record, err := game.GameRecord()
+		if err != nil {
+			http.Error(w, err.Error(), 403)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(record.String()))
+	}
+}
+
+func GameAnalysisHandler(s *GameServer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gameId := r.PathValue("gameId")
+		game, exists := s.GameManager.GetGame(gameId)
+		if !exists {
+			http.Error(w, "Game not found with ID: "+gameId, 404)
+			return
+		}
+
+		report, err := game.Analysis()
+		if err != nil {
+			http.Error(w, err.Error(), 403)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(report)
+	}
+}
@@ -12,5 +12,8 @@ func NewRouter(s *GameServer) *http.ServeMux {
 	router.HandleFunc("/ws/{gameId}", ConnectHandler(s))
 	router.HandleFunc("POST /game", CreateGameHandler(s))
 	router.HandleFunc("POST /game/{gameId}", JoinGameHandler(s))
+	router.HandleFunc("GET /game/{gameId}/events", GameEventsHandler(s))
+	router.HandleFunc("GET /game/{gameId}/record", GameRecordHandler(s))
+	router.HandleFunc("GET /game/{gameId}/analysis", GameAnalysisHandler(s))
 	return router
 }
@@ -0,0 +1,161 @@
+package simulate
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/it-ankka/battleline/internal/bot"
+	"github.com/it-ankka/battleline/internal/gamelogic"
+)
+
+type GameResult struct {
+	Seed        uint64 `json:"seed,string"`
+	FirstPlayer int    `json:"firstPlayer"`
+	Winner      int    `json:"winner"`
+	Turns       int    `json:"turns"`
+	Flags       [2]int `json:"flags"`
+}
+
+// Statistics of a batch of games. Players are counted by seat, so player one is
+// always the first strategy regardless of who started the game.
+type Summary struct {
+	Players            [2]string                          `json:"players"`
+	Games              int                                `json:"games"`
+	Wins               [2]int                             `json:"wins"`
+	Draws              int                                `json:"draws"`
+	WinRate            [2]float64                         `json:"winRate"`
+	FirstPlayerWinRate float64                            `json:"firstPlayerWinRate"`
+	AverageTurns       float64                            `json:"averageTurns"`
+	AverageFlags       [2]float64                         `json:"averageFlags"`
+	LaneClaims         [len(gamelogic.GameLanes{})][2]int `json:"laneClaims"`
+	// How often each formation was completed on either side of a lane by the end of a game
+	Formations map[string]int `json:"formations"`
+	Results    []GameResult   `json:"results"`
+}
+
+// Plays a single game to the end. Each strategy only sees its own private game state.
+func PlayGame(players [2]bot.Strategy, seed uint64) (*gamelogic.GameState, error) {
+	gs := gamelogic.NewGameStateWithSeed(seed)
+	for !gs.IsOver() {
+		state := gs.GetPrivateGameState(gs.ActivePlayer)
+		move := players[gs.ActivePlayer].ChooseMove(state)
+		if err := gs.ValidatePlayerMove(gs.ActivePlayer, &move); err != nil {
+			return gs, fmt.Errorf("seed %d, turn %d, player %d move %s: %w", seed, gs.Turn+1, gs.ActivePlayer+1, move, err)
+		}
+		gs.ExecutePlayerMove(gs.ActivePlayer, &move)
+	}
+	return gs, nil
+}
+
+// Plays the given number of games with consecutive seeds starting from seed
+func Run(names [2]string, players [2]bot.Strategy, games int, seed uint64) (*Summary, error) {
+	summary := &Summary{
+		Players:    names,
+		Formations: map[string]int{},
+		Results:    []GameResult{},
+	}
+	firstPlayerWins := 0
+
+	for i := range games {
+		gameSeed := seed + uint64(i)
+		gs, err := PlayGame(players, gameSeed)
+		if err != nil {
+			return nil, err
+		}
+
+		result := GameResult{
+			Seed:        gameSeed,
+			FirstPlayer: gs.Events[0].Player,
+			Winner:      gs.Winner(),
+			Turns:       gs.Turn + 1,
+		}
+		for laneIdx := range gs.Lanes {
+			lane := &gs.Lanes[laneIdx]
+			if lane.Claimed != gamelogic.NotClaimed {
+				result.Flags[lane.Claimed-1]++
+				summary.LaneClaims[laneIdx][lane.Claimed-1]++
+			}
+			for playerIdx := range lane.Cards {
+				if lane.IsSideComplete(playerIdx) {
+					summary.Formations[lane.Cards[playerIdx].GetFormation().String()]++
+				}
+			}
+		}
+
+		if result.Winner == gamelogic.NoWinner {
+			summary.Draws++
+		} else {
+			summary.Wins[result.Winner]++
+		}
+		if result.Winner == result.FirstPlayer {
+			firstPlayerWins++
+		}
+		summary.AverageTurns += float64(result.Turns)
+		for playerIdx, flags := range result.Flags {
+			summary.AverageFlags[playerIdx] += float64(flags)
+		}
+		summary.Results = append(summary.Results, result)
+	}
+
+	summary.Games = games
+	if games > 0 {
+		for playerIdx := range summary.Wins {
+			summary.WinRate[playerIdx] = float64(summary.Wins[playerIdx]) / float64(games)
+			summary.AverageFlags[playerIdx] /= float64(games)
+		}
+		summary.FirstPlayerWinRate = float64(firstPlayerWins) / float64(games)
+		summary.AverageTurns /= float64(games)
+	}
+	return summary, nil
+}
+
+func (s *Summary) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// Writes the statistics as metric and value rows. Results of individual games are left out.
+func (s *Summary) WriteCSV(w io.Writer) error {
+	formatFloat := func(f float64) string { return strconv.FormatFloat(f, 'f', 4, 64) }
+
+	rows := [][]string{
+		{"metric", "value"},
+		{"player_1", s.Players[0]},
+		{"player_2", s.Players[1]},
+		{"games", strconv.Itoa(s.Games)},
+		{"draws", strconv.Itoa(s.Draws)},
+		{"first_player_win_rate", formatFloat(s.FirstPlayerWinRate)},
+		{"average_turns", formatFloat(s.AverageTurns)},
+	}
+	for playerIdx := range s.Wins {
+		prefix := fmt.Sprintf("player_%d_", playerIdx+1)
+		rows = append(rows,
+			[]string{prefix + "wins", strconv.Itoa(s.Wins[playerIdx])},
+			[]string{prefix + "win_rate", formatFloat(s.WinRate[playerIdx])},
+			[]string{prefix + "average_flags", formatFloat(s.AverageFlags[playerIdx])},
+		)
+	}
+	for laneIdx, claims := range s.LaneClaims {
+		for playerIdx, count := range claims {
+			rows = append(rows, []string{fmt.Sprintf("lane_%d_player_%d_claims", laneIdx+1, playerIdx+1), strconv.Itoa(count)})
+		}
+	}
+	formations := []string{}
+	for formation := range s.Formations {
+		formations = append(formations, formation)
+	}
+	sort.Strings(formations)
+	for _, formation := range formations {
+		rows = append(rows, []string{"formation_" + strings.ToLower(formation), strconv.Itoa(s.Formations[formation])})
+	}
+
+	writer := csv.NewWriter(w)
+	writer.WriteAll(rows)
+	return writer.Error()
+}
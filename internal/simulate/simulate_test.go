@@ -0,0 +1,66 @@
+package simulate_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/it-ankka/battleline/internal/bot"
+	"github.com/it-ankka/battleline/internal/simulate"
+)
+
+func TestRun(t *testing.T) {
+	const games = 2
+	names := [2]string{"greedy", "random"}
+	players := [2]bot.Strategy{bot.NewGreedyBot(), bot.NewRandomBot(1)}
+
+	summary, err := simulate.Run(names, players, games, 1)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if summary.Games != games || len(summary.Results) != games {
+		t.Fatalf("Run() played %d games with %d results, want %d", summary.Games, len(summary.Results), games)
+	}
+	if total := summary.Wins[0] + summary.Wins[1] + summary.Draws; total != games {
+		t.Errorf("wins %v and %d draws sum to %d, want %d", summary.Wins, summary.Draws, total, games)
+	}
+	if len(summary.Formations) == 0 {
+		t.Error("Run() counted no formations")
+	}
+
+	var out bytes.Buffer
+	if err := summary.WriteJSON(&out); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	var decoded simulate.Summary
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("WriteJSON() wrote invalid JSON: %v", err)
+	}
+	if decoded.Wins != summary.Wins || len(decoded.Formations) != len(summary.Formations) {
+		t.Errorf("WriteJSON() round trip = %+v, want %+v", decoded, *summary)
+	}
+
+	out.Reset()
+	if err := summary.WriteCSV(&out); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("WriteCSV() wrote invalid CSV: %v", err)
+	}
+	values := map[string]string{}
+	for _, row := range rows {
+		values[row[0]] = row[1]
+	}
+	if values["games"] != "2" || values["player_1"] != "greedy" {
+		t.Errorf("WriteCSV() games = %q, player_1 = %q", values["games"], values["player_1"])
+	}
+	for formation, count := range summary.Formations {
+		if values["formation_"+strings.ToLower(formation)] != strconv.Itoa(count) {
+			t.Errorf("WriteCSV() has no row for formation %s = %d", formation, count)
+		}
+	}
+}
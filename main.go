@@ -1,20 +1,22 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/it-ankka/battleline/internal/bot"
 	"github.com/it-ankka/battleline/internal/gameserver"
 	"github.com/it-ankka/battleline/internal/middleware"
 	"github.com/it-ankka/battleline/internal/router"
+	"github.com/it-ankka/battleline/internal/simulate"
 )
 
 func main() {
-	address := ":8080"
-
 	// Add contextual information here
 	defaultAttrs := []slog.Attr{}
 
@@ -44,6 +46,28 @@ func main() {
 	logger := slog.New(slogHandler)
 	slog.SetDefault(logger)
 
+	command := "serve"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	switch command {
+	case "serve":
+		serve()
+	case "simulate":
+		if err := simulateGames(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q. Available commands: serve, simulate\n", command)
+		os.Exit(2)
+	}
+}
+
+func serve() {
+	address := ":8080"
+
 	server := gameserver.NewGameServer()
 	r := router.NewRouter(server)
 
@@ -55,3 +79,50 @@ func main() {
 
 	http.ListenAndServe(address, stack(r))
 }
+
+// Plays games between strategies without a server and writes the statistics
+func simulateGames(args []string) error {
+	flags := flag.NewFlagSet("simulate", flag.ExitOnError)
+	games := flags.Int("games", 100, "number of games to play")
+	seed := flags.Uint64("seed", 1, "seed of the first game, later games use consecutive seeds")
+	strategies := strings.Join(bot.Names(), ", ")
+	player1 := flags.String("p1", "random", "strategy of player one ("+strategies+")")
+	player2 := flags.String("p2", "random", "strategy of player two ("+strategies+")")
+	format := flags.String("format", "json", "output format (json, csv)")
+	output := flags.String("out", "", "output file, standard output by default")
+	flags.Parse(args)
+
+	names := [2]string{*player1, *player2}
+	players := [2]bot.Strategy{}
+	for i, name := range names {
+		strategy, err := bot.New(name, *seed+uint64(i))
+		if err != nil {
+			return fmt.Errorf("%w %q", err, name)
+		}
+		players[i] = strategy
+	}
+
+	summary, err := simulate.Run(names, players, *games, *seed)
+	if err != nil {
+		return err
+	}
+
+	w := os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	switch *format {
+	case "json":
+		return summary.WriteJSON(w)
+	case "csv":
+		return summary.WriteCSV(w)
+	default:
+		return fmt.Errorf("Unknown format %q.", *format)
+	}
+}
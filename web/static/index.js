@@ -11,6 +11,9 @@ const readyForm = document.getElementById("ready-form");
 const copyGameIdInput = document.getElementById("game-id-input");
 const joinGameInput = document.getElementById("join-game-id-input");
 const chatInput = document.getElementById("chat-input");
+const opponentSelect = document.getElementById("opponent-select");
+const hintsCheckbox = document.getElementById("hints-checkbox");
+const trainingWheelsCheckbox = document.getElementById("training-wheels-checkbox");
 
 let conn;
 let isReady = false;
@@ -20,6 +23,20 @@ window.addEventListener("unload", () => {
 });
 
 // These are for debugging
+window.placeTactic = (tactic, lane) => {
+  let m = {
+    type: "move",
+    data: {
+      move: {
+        action: "placement",
+        lane: lane,
+        card: { tactic: tactic },
+      },
+    },
+  };
+  window.conn.send(JSON.stringify(m));
+};
+
 window.placeCard = (suit, value, lane) => {
   let m = {
     type: "move",
@@ -47,19 +64,50 @@ window.claim = (lane) => {
   window.conn.send(JSON.stringify(m));
 };
 
-window.drawCard = () => {
+window.endClaim = () => {
+  let m = {
+    type: "move",
+    data: {
+      move: {
+        action: "end_claim",
+      },
+    },
+  };
+  window.conn.send(JSON.stringify(m));
+};
+
+window.pass = () => {
+  let m = {
+    type: "move",
+    data: {
+      move: {
+        action: "pass",
+      },
+    },
+  };
+  window.conn.send(JSON.stringify(m));
+};
+
+window.drawCard = (tacticsDeck = false) => {
   let m = {
     type: "move",
     data: {
       move: {
         action: "draw",
-        tacticsDeck: false,
+        tacticsDeck: tacticsDeck,
       },
     },
   };
   window.conn.send(JSON.stringify(m));
 };
 
+window.hint = () => {
+  let m = {
+    type: "hint",
+  };
+  window.conn.send(JSON.stringify(m));
+};
+
 function logMessage(msg) {
   messageLog.innerText += `[${new Date().toLocaleTimeString()}] ${msg}\n`;
 }
@@ -139,12 +187,32 @@ function handleServerMessage(data) {
       readyForm.hidden = true;
       break;
 
+    case "session_end":
+      if (data.state?.winner >= 0) {
+        logMessage(`🏁 Game over! Player ${data.state.winner + 1} won!`);
+      } else {
+        logMessage("🏁 Game over! Neither player can move.");
+      }
+      break;
+
     case "client_chat":
       break;
 
     case "sync":
       break;
 
+    case "hint":
+      for (const hint of data.hints ?? []) {
+        logMessage(`💡 ${JSON.stringify(hint.move)}: ${hint.reason}`);
+      }
+      break;
+
+    case "blunder":
+      for (const hint of data.hints ?? []) {
+        logMessage(`❗ Better was ${JSON.stringify(hint.move)}: ${hint.reason}`);
+      }
+      break;
+
     case "error":
       logMessage(`⚠️ Error: ${JSON.stringify(data.error)}`);
       break;
@@ -175,7 +243,18 @@ async function joinGame(gameId) {
 
 async function createGameSubmitHandler(e) {
   e.preventDefault();
-  const response = await fetch("/game", {
+  const params = new URLSearchParams();
+  if (opponentSelect.value !== "human") {
+    params.set("opponent", "bot");
+    params.set("difficulty", opponentSelect.value);
+  }
+  if (hintsCheckbox.checked) {
+    params.set("hints", "true");
+  }
+  if (trainingWheelsCheckbox.checked) {
+    params.set("trainingWheels", "true");
+  }
+  const response = await fetch(`/game?${params}`, {
     method: "POST",
     credentials: "same-origin",
   });